@@ -23,8 +23,10 @@ import (
 	"github.com/Netcracker/qubership-disaster-recovery-daemon/server"
 	"k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/apimachinery/pkg/runtime"
 	"log"
+	"os"
+	"os/signal"
+	"syscall"
 	"time"
 )
 
@@ -40,9 +42,11 @@ func main() {
 
 	// Easy way to create a kubernetes client if necessary
 	kubeClient := client.MakeKubeClientSet()
+	dynClient := client.MakeDynamicClient()
 
 	// Start DRD server with custom health function inside, which calculates on;y additional health status (fullHealth: false)
 	go server.NewServer(cfg).
+		WithClients(kubeClient, dynClient).
 		WithHealthFunc(func(request entity.HealthRequest) (entity.HealthResponse, error) {
 			_, err := kubeClient.CoreV1().Pods("consul-service").Get(context.TODO(), "consul-server-0", metav1.GetOptions{})
 			if err != nil {
@@ -52,25 +56,29 @@ func main() {
 		}, false).
 		Run()
 
+	// Stop DRD controller gracefully on SIGTERM
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
 	// Start DRD controller with external function
-	controller.NewController(cfg).
+	err = controller.NewTypedController[v1.ConfigMap](cfg).
 		WithFunc(drFunction).
 		WithRetry(3, time.Second*1).
-		Run()
+		WithDynamicClient(dynClient).
+		WithKubeClient(kubeClient).
+		Run(ctx)
+	if err != nil {
+		log.Fatalln(err.Error())
+	}
 }
 
 // DR function
-func drFunction(controllerRequest entity.ControllerRequest) (entity.ControllerResponse, error) {
-	var configMap v1.ConfigMap
-	err := runtime.DefaultUnstructuredConverter.FromUnstructured(controllerRequest.Object, &configMap)
-	if err != nil {
-		log.Printf("Error: %s", err)
-		return entity.ControllerResponse{}, err
-	}
+func drFunction(request controller.TypedRequest[v1.ConfigMap]) (entity.ControllerResponse, error) {
+	log.Printf("DR config map data: %v", request.Resource.Data)
 
 	return entity.ControllerResponse{
 		SwitchoverState: entity.SwitchoverState{
-			Mode:    controllerRequest.Mode,
+			Mode:    request.Mode,
 			Status:  "done",
 			Comment: "done",
 		},
@@ -14,7 +14,12 @@
 
 package entity
 
-import "k8s.io/apimachinery/pkg/watch"
+import (
+	"errors"
+	"time"
+
+	"k8s.io/apimachinery/pkg/watch"
+)
 
 const (
 	ACTIVE          = "active"
@@ -31,15 +36,60 @@ const (
 	StatefulsetType = "statefulset"
 )
 
+// Verdicts of dry-run switchover
+const (
+	DryRunPassed = "passed"
+	DryRunFailed = "failed"
+)
+
+// ErrResourceNotFound is returned when DR resource does not exist, e.g. it was deleted and is not recreated yet.
+var ErrResourceNotFound = errors.New("DR resource not found")
+
+// ErrNoSwitchoverInProgress is returned when switchover cancellation is requested, but switchover is not queued or running.
+var ErrNoSwitchoverInProgress = errors.New("there is no switchover in progress")
+
+// ErrOperationNotFound is returned when switchover operation with the requested ID is not kept in DR resource status.
+var ErrOperationNotFound = errors.New("switchover operation not found")
+
+// Phases of switchover operation
+const (
+	OperationPending   = "pending"
+	OperationRunning   = "running"
+	OperationSucceeded = "succeeded"
+	OperationFailed    = "failed"
+)
+
+// Types of conditions which are maintained in DR resource status
+const (
+	SwitchoverInProgressCondition = "SwitchoverInProgress"
+	SwitchoverSucceededCondition  = "SwitchoverSucceeded"
+	HealthyCondition              = "Healthy"
+)
+
+// Reasons of Kubernetes Events which are emitted for DR resource on switchover transitions
+const (
+	SwitchoverQueuedReason          = "SwitchoverQueued"
+	SwitchoverStartedReason         = "SwitchoverStarted"
+	SwitchoverRetriedReason         = "SwitchoverRetried"
+	SwitchoverSucceededReason       = "SwitchoverSucceeded"
+	SwitchoverFailedReason          = "SwitchoverFailed"
+	SwitchoverRolledBackReason      = "SwitchoverRolledBack"
+	SwitchoverRollbackFailedReason  = "SwitchoverRollbackFailed"
+	SwitchoverCancelRequestedReason = "SwitchoverCancelRequested"
+	SwitchoverCancelledReason       = "SwitchoverCancelled"
+)
+
 type SwitchoverState struct {
-	Mode    string `json:"mode"`
-	Status  string `json:"status,omitempty"`
-	Comment string `json:"comment,omitempty"`
+	Mode        string `json:"mode"`
+	Status      string `json:"status,omitempty"`
+	Comment     string `json:"comment,omitempty"`
+	OperationID string `json:"operationId,omitempty"`
 }
 
 type RequestData struct {
 	Mode   string `json:"mode"`
 	NoWait *bool  `json:"no-wait,omitempty"`
+	DryRun bool   `json:"dryRun,omitempty"`
 }
 
 type ControllerRequest struct {
@@ -48,10 +98,43 @@ type ControllerRequest struct {
 	Status               SwitchoverState        `json:"status"`
 	EventType            watch.EventType        `json:"eventType"`
 	Object               map[string]interface{} `json:"object"`
+	OldObject            map[string]interface{} `json:"oldObject,omitempty"`
+	PreviousMode         string                 `json:"previousMode,omitempty"`
+	RetryRequested       bool                   `json:"retryRequested,omitempty"`
+	Diff                 []FieldChange          `json:"diff,omitempty"`
+	Attempt              int                    `json:"attempt"`
+	DryRun               bool                   `json:"dryRun,omitempty"`
+	Progress             ProgressReporter       `json:"-"`
+}
+
+// FieldChange is a difference between the previous and the current state of DR resource. Path is a dot-separated path
+// to the changed field, OldValue is nil for added field and NewValue is nil for removed field.
+type FieldChange struct {
+	Path     string      `json:"path"`
+	OldValue interface{} `json:"oldValue,omitempty"`
+	NewValue interface{} `json:"newValue,omitempty"`
+}
+
+// DryRunResult is a verdict of dry-run switchover. It is written to DR resource status and returned to the caller.
+type DryRunResult struct {
+	ID       string   `json:"id,omitempty"`
+	Mode     string   `json:"mode"`
+	Verdict  string   `json:"verdict"`
+	Comment  string   `json:"comment,omitempty"`
+	Findings []string `json:"findings,omitempty"`
+}
+
+// ProgressReporter writes intermediate state of running switchover to DR resource status.
+type ProgressReporter interface {
+	Report(step string, percent int, comment string)
 }
 
+// ControllerResponse is a result of DR function. If InProgress is set or RequeueAfter is positive,
+// the switchover stays running and DR function is called again after RequeueAfter delay.
 type ControllerResponse struct {
 	SwitchoverState
+	InProgress   bool          `json:"inProgress,omitempty"`
+	RequeueAfter time.Duration `json:"requeueAfter,omitempty"`
 }
 
 type HealthRequest struct {
@@ -70,6 +153,41 @@ type ModeDataUpdate struct {
 	Annotation map[string]string
 }
 
+// StatusField is an additional value which is written to DR resource status by the given path.
+type StatusField struct {
+	Path  []string
+	Value interface{}
+}
+
+// SwitchoverRecord describes one switchover in the history which is kept in DR resource status.
+type SwitchoverRecord struct {
+	Mode         string `json:"mode,omitempty"`
+	PreviousMode string `json:"previousMode,omitempty"`
+	Status       string `json:"status"`
+	Comment      string `json:"comment,omitempty"`
+	StartTime    string `json:"startTime,omitempty"`
+	EndTime      string `json:"endTime,omitempty"`
+	Duration     string `json:"duration,omitempty"`
+}
+
+// Operation is a switchover requested via DRD server. It is kept in DR resource status, so the caller can check
+// the result of asynchronous request by the operation ID. Status and Comment are the last switchover status.
+type Operation struct {
+	ID             string `json:"id"`
+	Mode           string `json:"mode"`
+	Phase          string `json:"phase"`
+	Status         string `json:"status,omitempty"`
+	Comment        string `json:"comment,omitempty"`
+	CreationTime   string `json:"creationTime"`
+	StartTime      string `json:"startTime,omitempty"`
+	CompletionTime string `json:"completionTime,omitempty"`
+}
+
+// IsFinished returns true if the operation has succeeded or failed.
+func (o Operation) IsFinished() bool {
+	return o.Phase == OperationSucceeded || o.Phase == OperationFailed
+}
+
 type StatusResponse struct {
 	Status  string `json:"status"`
 	Message string `json:"message,omitempty"`
@@ -15,12 +15,17 @@
 package main
 
 import (
+	"context"
+	"github.com/Netcracker/qubership-disaster-recovery-daemon/client"
 	"github.com/Netcracker/qubership-disaster-recovery-daemon/config"
+	"github.com/Netcracker/qubership-disaster-recovery-daemon/controller"
 	"github.com/Netcracker/qubership-disaster-recovery-daemon/server"
 	"github.com/Netcracker/qubership-disaster-recovery-daemon/utils"
 	"log"
 	"log/slog"
 	"os"
+	"os/signal"
+	"syscall"
 )
 
 func main() {
@@ -32,5 +37,39 @@ func main() {
 	if err != nil {
 		log.Fatalln(err.Error())
 	}
-	server.NewServer(cfg).Run()
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	ctr := newController(cfg)
+	if ctr == nil {
+		server.NewServer(cfg).WithContext(ctx).Run()
+		return
+	}
+	// Stock DR controller is executed in the same process with DRD server
+	kubeClient := client.MakeKubeClientSet()
+	dynClient := client.MakeDynamicClient()
+	go server.NewServer(cfg).WithContext(ctx).WithClients(kubeClient, dynClient).Run()
+	err = ctr.WithDynamicClient(dynClient).
+		WithKubeClient(kubeClient).
+		Run(ctx)
+	if err != nil {
+		log.Fatalln(err.Error())
+	}
+}
+
+// newController returns DR controller with DR function which is configured with environment variables
+// or nil if DR controller should not be started.
+func newController(cfg *config.Config) *controller.Controller {
+	switch {
+	case cfg.PlanConfigMap != "":
+		return controller.NewController(cfg).WithPlanConfigMap(cfg.PlanConfigMap)
+	case len(cfg.Command) > 0:
+		return controller.NewController(cfg).WithContextFunc(controller.CommandFunc(cfg.Command[0], cfg.Command[1:]...))
+	case cfg.WebhookURL != "":
+		headers := map[string]string{}
+		if cfg.WebhookToken != "" {
+			headers["Authorization"] = "Bearer " + cfg.WebhookToken
+		}
+		return controller.NewController(cfg).WithContextFunc(controller.WebhookFunc(cfg.WebhookURL, headers))
+	}
+	return nil
 }
@@ -42,12 +42,21 @@ func NewConfig(configLoader ConfigLoader) (*Config, error) {
 		return nil, err
 	}
 
+	controllerConfig := &ControllerConfig{StaleSwitchoverPolicy: ResumeStaleSwitchover}
+	if controllerConfigLoader, ok := configLoader.(ControllerConfigLoader); ok {
+		controllerConfig, err = controllerConfigLoader.GetControllerConfig()
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	cfg := &Config{
 		*crCfg,
 		*healthConfig,
 		*drp,
 		*auth,
 		*serverConfig,
+		*controllerConfig,
 	}
 	return cfg, nil
 }
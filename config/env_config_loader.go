@@ -21,6 +21,7 @@ import (
 	"os"
 	"strconv"
 	"strings"
+	"time"
 )
 
 type EnvProvider interface {
@@ -82,13 +83,41 @@ func (decl DefaultEnvConfigLoader) GetDisasterRecoveryPaths() (*DisasterRecovery
 	if err != nil {
 		return nil, err
 	}
+	drStatusLeaderPath := decl.getOptionalPathEnv("DISASTER_RECOVERY_STATUS_LEADER_PATH")
+	drStatusStartTimePath := decl.getOptionalPathEnv("DISASTER_RECOVERY_STATUS_START_TIME_PATH")
+	drStatusOwnerPath := decl.getOptionalPathEnv("DISASTER_RECOVERY_STATUS_OWNER_PATH")
+	drStatusConditionsPath := decl.getOptionalPathEnv("DISASTER_RECOVERY_STATUS_CONDITIONS_PATH")
+	drStatusHistoryPath := decl.getOptionalPathEnv("DISASTER_RECOVERY_STATUS_HISTORY_PATH")
+	drStatusRolledBackPath := decl.getOptionalPathEnv("DISASTER_RECOVERY_STATUS_ROLLED_BACK_PATH")
+	drStatusDryRunPath := decl.getOptionalPathEnv("DISASTER_RECOVERY_STATUS_DRY_RUN_PATH")
+	drStatusOperationsPath := decl.getOptionalPathEnv("DISASTER_RECOVERY_STATUS_OPERATIONS_PATH")
+	drStatusObservedGenerationPath := decl.getOptionalPathEnv("DISASTER_RECOVERY_STATUS_OBSERVED_GENERATION_PATH")
+	drHistoryLength, err := strconv.Atoi(decl.envProvider.GetEnv("DISASTER_RECOVERY_HISTORY_LENGTH", "10"))
+	if err != nil || drHistoryLength <= 0 {
+		return nil, errors.New("DISASTER_RECOVERY_HISTORY_LENGTH environment variable must be a positive number")
+	}
+	drOperationsRetention, err := time.ParseDuration(decl.envProvider.GetEnv("DISASTER_RECOVERY_OPERATIONS_RETENTION", "24h"))
+	if err != nil || drOperationsRetention <= 0 {
+		return nil, errors.New("DISASTER_RECOVERY_OPERATIONS_RETENTION environment variable must be a positive duration, e.g. '24h'")
+	}
 	if strings.ToLower(useDefaultPaths) == "true" {
 		return &DisasterRecoveryPath{
 			DisasterRecoveryStatusPath{
-				[]string{"status", "disasterRecoveryStatus", "mode"},
-				[]string{"status", "disasterRecoveryStatus", "status"},
-				[]string{"status", "disasterRecoveryStatus", "comment"},
-				treatStatusAsField,
+				ModePath:               []string{"status", "disasterRecoveryStatus", "mode"},
+				StatusPath:             []string{"status", "disasterRecoveryStatus", "status"},
+				CommentPath:            []string{"status", "disasterRecoveryStatus", "comment"},
+				LeaderPath:             drStatusLeaderPath,
+				StartTimePath:          drStatusStartTimePath,
+				OwnerPath:              drStatusOwnerPath,
+				ConditionsPath:         drStatusConditionsPath,
+				HistoryPath:            drStatusHistoryPath,
+				HistoryLength:          drHistoryLength,
+				RolledBackPath:         drStatusRolledBackPath,
+				DryRunPath:             drStatusDryRunPath,
+				OperationsPath:         drStatusOperationsPath,
+				OperationsRetention:    drOperationsRetention,
+				ObservedGenerationPath: drStatusObservedGenerationPath,
+				TreatStatusAsField:     treatStatusAsField,
 			},
 			[]string{"spec", "disasterRecovery", "mode"},
 			[]string{"spec", "disasterRecovery", "noWait"},
@@ -119,16 +148,23 @@ func (decl DefaultEnvConfigLoader) GetDisasterRecoveryPaths() (*DisasterRecovery
 		return nil, err
 	}
 	drStatusStatusPath := strings.Split(drStatusStatusPathString, ".")
-	drStatusCommentPathString := decl.envProvider.GetEnv("DISASTER_RECOVERY_STATUS_COMMENT_PATH", "")
-	var drStatusCommentPath []string
-	if drStatusCommentPathString != "" {
-		drStatusCommentPath = strings.Split(drStatusCommentPathString, ".")
-	}
+	drStatusCommentPath := decl.getOptionalPathEnv("DISASTER_RECOVERY_STATUS_COMMENT_PATH")
 	drStatusPath := &DisasterRecoveryStatusPath{
-		ModePath:           drStatusModePath,
-		StatusPath:         drStatusStatusPath,
-		CommentPath:        drStatusCommentPath,
-		TreatStatusAsField: treatStatusAsField,
+		ModePath:               drStatusModePath,
+		StatusPath:             drStatusStatusPath,
+		CommentPath:            drStatusCommentPath,
+		LeaderPath:             drStatusLeaderPath,
+		StartTimePath:          drStatusStartTimePath,
+		OwnerPath:              drStatusOwnerPath,
+		ConditionsPath:         drStatusConditionsPath,
+		HistoryPath:            drStatusHistoryPath,
+		HistoryLength:          drHistoryLength,
+		RolledBackPath:         drStatusRolledBackPath,
+		DryRunPath:             drStatusDryRunPath,
+		OperationsPath:         drStatusOperationsPath,
+		OperationsRetention:    drOperationsRetention,
+		ObservedGenerationPath: drStatusObservedGenerationPath,
+		TreatStatusAsField:     treatStatusAsField,
 	}
 
 	drp := &DisasterRecoveryPath{
@@ -221,15 +257,61 @@ func (decl DefaultEnvConfigLoader) GetServerConfig() (*ServerConfig, error) {
 	if err != nil {
 		return nil, err
 	}
+	metricsPort, err := strconv.Atoi(decl.envProvider.GetEnv("METRICS_PORT", portEnv))
+	if err != nil {
+		return nil, fmt.Errorf("METRICS_PORT environment variable must be a number: %w", err)
+	}
+	switchoverWaitTimeout, err := time.ParseDuration(decl.envProvider.GetEnv("SWITCHOVER_WAIT_TIMEOUT", "10m"))
+	if err != nil || switchoverWaitTimeout <= 0 {
+		return nil, errors.New("SWITCHOVER_WAIT_TIMEOUT environment variable must be a positive duration, e.g. '10m'")
+	}
+	dryRunTimeout, err := time.ParseDuration(decl.envProvider.GetEnv("DRY_RUN_TIMEOUT", "1m"))
+	if err != nil || dryRunTimeout <= 0 {
+		return nil, errors.New("DRY_RUN_TIMEOUT environment variable must be a positive duration, e.g. '1m'")
+	}
 	certsPath := strings.TrimSuffix(decl.envProvider.GetEnv("CERTS_PATH", "/tls/"), "/")
 	return &ServerConfig{
-		Port:       port,
-		Suites:     suites,
-		TLSEnabled: tlsEnabled,
-		CertsPath:  certsPath,
+		Port:                  port,
+		Suites:                suites,
+		TLSEnabled:            tlsEnabled,
+		CertsPath:             certsPath,
+		MetricsPort:           metricsPort,
+		SwitchoverWaitTimeout: switchoverWaitTimeout,
+		DryRunTimeout:         dryRunTimeout,
 	}, nil
 }
 
+func (decl DefaultEnvConfigLoader) GetControllerConfig() (*ControllerConfig, error) {
+	switchoverTimeout, err := time.ParseDuration(decl.envProvider.GetEnv("SWITCHOVER_TIMEOUT", "0s"))
+	if err != nil {
+		return nil, fmt.Errorf("SWITCHOVER_TIMEOUT environment variable must be a duration, e.g. '30m': %w", err)
+	}
+	staleSwitchoverPolicy := strings.ToLower(decl.envProvider.GetEnv("STALE_SWITCHOVER_POLICY", ResumeStaleSwitchover))
+	if staleSwitchoverPolicy != ResumeStaleSwitchover && staleSwitchoverPolicy != FailStaleSwitchover {
+		return nil, fmt.Errorf("STALE_SWITCHOVER_POLICY environment variable must be '%s' or '%s'",
+			ResumeStaleSwitchover, FailStaleSwitchover)
+	}
+	controllerConfig := &ControllerConfig{
+		SwitchoverTimeout:     switchoverTimeout,
+		StaleSwitchoverPolicy: staleSwitchoverPolicy,
+		PlanConfigMap:         decl.envProvider.GetEnv("SWITCHOVER_PLAN_CONFIG_MAP", ""),
+		Command:               strings.Fields(decl.envProvider.GetEnv("SWITCHOVER_COMMAND", "")),
+		WebhookURL:            decl.envProvider.GetEnv("SWITCHOVER_WEBHOOK_URL", ""),
+		WebhookToken:          decl.envProvider.GetEnv("SWITCHOVER_WEBHOOK_TOKEN", ""),
+	}
+	functions := 0
+	for _, isSet := range []bool{controllerConfig.PlanConfigMap != "", len(controllerConfig.Command) > 0, controllerConfig.WebhookURL != ""} {
+		if isSet {
+			functions++
+		}
+	}
+	if functions > 1 {
+		return nil, fmt.Errorf("only one of SWITCHOVER_PLAN_CONFIG_MAP, SWITCHOVER_COMMAND and SWITCHOVER_WEBHOOK_URL " +
+			"environment variables can be specified")
+	}
+	return controllerConfig, nil
+}
+
 func getCipherSuites(decl DefaultEnvConfigLoader) ([]uint16, error) {
 	allSuites := decl.envProvider.GetEnv("CIPHER_SUITES", "")
 	var suites []uint16
@@ -265,6 +347,14 @@ func (decl DefaultEnvConfigLoader) getRequiredEnv(key string) (string, error) {
 	return value, nil
 }
 
+func (decl DefaultEnvConfigLoader) getOptionalPathEnv(key string) []string {
+	value := decl.envProvider.GetEnv(key, "")
+	if value == "" {
+		return nil
+	}
+	return strings.Split(value, ".")
+}
+
 func (decl DefaultEnvConfigLoader) getServicesEnv(key string, allowTypes ...string) (map[string][]string, error) {
 	value := decl.envProvider.GetEnv(key, "")
 	if value == "" {
@@ -14,7 +14,11 @@
 
 package config
 
-import "testing"
+import (
+	"reflect"
+	"testing"
+	"time"
+)
 
 func NewTestEnvProvider(envs map[string]string) TestEnvProvider {
 	return TestEnvProvider{envs: envs}
@@ -39,3 +43,91 @@ func TestAuthEnabledIsFalse(t *testing.T) {
 		t.Fatalf("authentication must be enabled")
 	}
 }
+
+func TestSwitchoverTimeout(t *testing.T) {
+	envs := map[string]string{"SWITCHOVER_TIMEOUT": "20m"}
+	cfgLoader := NewEnvConfigLoader(NewTestEnvProvider(envs))
+	controllerConfig, err := cfgLoader.GetControllerConfig()
+	if err != nil || controllerConfig.SwitchoverTimeout != 20*time.Minute {
+		t.Fatalf("switchover timeout must be 20m, but got %v, error: %v", controllerConfig, err)
+	}
+
+	envs["SWITCHOVER_TIMEOUT"] = "20"
+	if _, err := cfgLoader.GetControllerConfig(); err == nil {
+		t.Fatalf("switchover timeout without unit must be rejected")
+	}
+}
+
+func TestControllerFunction(t *testing.T) {
+	envs := map[string]string{"SWITCHOVER_COMMAND": "/bin/sh /scripts/switchover.sh"}
+	cfgLoader := NewEnvConfigLoader(NewTestEnvProvider(envs))
+	controllerConfig, err := cfgLoader.GetControllerConfig()
+	if err != nil || !reflect.DeepEqual(controllerConfig.Command, []string{"/bin/sh", "/scripts/switchover.sh"}) {
+		t.Fatalf("command must be split into arguments, but got %v, error: %v", controllerConfig, err)
+	}
+
+	envs["SWITCHOVER_WEBHOOK_URL"] = "http://my-service:8080/switchover"
+	if _, err := cfgLoader.GetControllerConfig(); err == nil {
+		t.Fatalf("both command and webhook must be rejected")
+	}
+}
+
+type testConfigLoader struct{}
+
+func (testConfigLoader) GetCustomResourceConfig() (*CustomResourceConfig, error) {
+	return &CustomResourceConfig{}, nil
+}
+
+func (testConfigLoader) GetDisasterRecoveryPaths() (*DisasterRecoveryPath, error) {
+	return &DisasterRecoveryPath{}, nil
+}
+
+func (testConfigLoader) GetHealthConfig() (*HealthConfig, error) {
+	return &HealthConfig{}, nil
+}
+
+func (testConfigLoader) GetAuthConfig() (*AuthConfig, error) {
+	return &AuthConfig{}, nil
+}
+
+func (testConfigLoader) GetServerConfig() (*ServerConfig, error) {
+	return &ServerConfig{}, nil
+}
+
+func TestDefaultControllerConfig(t *testing.T) {
+	cfg, err := NewConfig(testConfigLoader{})
+	if err != nil {
+		t.Fatalf("config must be loaded without controller configuration, error: %v", err)
+	}
+	if cfg.SwitchoverTimeout != 0 || cfg.StaleSwitchoverPolicy != ResumeStaleSwitchover {
+		t.Fatalf("controller configuration must have default values, but got %v", cfg.ControllerConfig)
+	}
+}
+
+func TestHistoryLength(t *testing.T) {
+	envs := map[string]string{"USE_DEFAULT_PATHS": "true", "DISASTER_RECOVERY_STATUS_HISTORY_PATH": "status.history"}
+	cfgLoader := NewEnvConfigLoader(NewTestEnvProvider(envs))
+	drPaths, err := cfgLoader.GetDisasterRecoveryPaths()
+	if err != nil || drPaths.StatusPath.HistoryLength != 10 || len(drPaths.StatusPath.HistoryPath) != 2 {
+		t.Fatalf("history must be kept by 'status.history' path with 10 records, but got %v, error: %v", drPaths, err)
+	}
+
+	envs["DISASTER_RECOVERY_HISTORY_LENGTH"] = "0"
+	if _, err := cfgLoader.GetDisasterRecoveryPaths(); err == nil {
+		t.Fatalf("zero history length must be rejected")
+	}
+}
+
+func TestOperationsRetention(t *testing.T) {
+	envs := map[string]string{"USE_DEFAULT_PATHS": "true", "DISASTER_RECOVERY_STATUS_OPERATIONS_PATH": "status.operations"}
+	cfgLoader := NewEnvConfigLoader(NewTestEnvProvider(envs))
+	drPaths, err := cfgLoader.GetDisasterRecoveryPaths()
+	if err != nil || drPaths.StatusPath.OperationsRetention != 24*time.Hour || len(drPaths.StatusPath.OperationsPath) != 2 {
+		t.Fatalf("operations must be kept by 'status.operations' path for 24h, but got %v, error: %v", drPaths, err)
+	}
+
+	envs["DISASTER_RECOVERY_OPERATIONS_RETENTION"] = "forever"
+	if _, err := cfgLoader.GetDisasterRecoveryPaths(); err == nil {
+		t.Fatalf("invalid operations retention must be rejected")
+	}
+}
@@ -14,10 +14,18 @@
 
 package config
 
-import "github.com/Netcracker/qubership-disaster-recovery-daemon/api/entity"
+import (
+	"time"
+
+	"github.com/Netcracker/qubership-disaster-recovery-daemon/api/entity"
+)
 
 const (
 	RequiredEnvTemplatedError = "the environment variable '%s' must not be empty"
+	// ResumeStaleSwitchover policy re-invokes DR function for switchover which was interrupted by controller restart
+	ResumeStaleSwitchover = "resume"
+	// FailStaleSwitchover policy marks switchover which was interrupted by controller restart as failed
+	FailStaleSwitchover = "fail"
 )
 
 type (
@@ -27,6 +35,7 @@ type (
 		DisasterRecoveryPath
 		AuthConfig
 		ServerConfig
+		ControllerConfig
 	}
 
 	CustomResourceConfig struct {
@@ -56,10 +65,21 @@ type (
 	}
 
 	DisasterRecoveryStatusPath struct {
-		ModePath           []string
-		StatusPath         []string
-		CommentPath        []string
-		TreatStatusAsField bool
+		ModePath               []string
+		StatusPath             []string
+		CommentPath            []string
+		LeaderPath             []string
+		StartTimePath          []string
+		OwnerPath              []string
+		ConditionsPath         []string
+		HistoryPath            []string
+		HistoryLength          int
+		RolledBackPath         []string
+		DryRunPath             []string
+		OperationsPath         []string
+		OperationsRetention    time.Duration
+		ObservedGenerationPath []string
+		TreatStatusAsField     bool
 	}
 
 	AuthConfig struct {
@@ -76,10 +96,22 @@ type (
 	}
 
 	ServerConfig struct {
-		Port       int
-		Suites     []uint16
-		TLSEnabled bool
-		CertsPath  string
+		Port                  int
+		Suites                []uint16
+		TLSEnabled            bool
+		CertsPath             string
+		MetricsPort           int
+		SwitchoverWaitTimeout time.Duration
+		DryRunTimeout         time.Duration
+	}
+
+	ControllerConfig struct {
+		SwitchoverTimeout     time.Duration
+		StaleSwitchoverPolicy string
+		PlanConfigMap         string
+		Command               []string
+		WebhookURL            string
+		WebhookToken          string
 	}
 )
 
@@ -91,8 +123,16 @@ type ConfigLoader interface {
 	GetServerConfig() (*ServerConfig, error)
 }
 
-type EnvConfigLoader interface {
+// ControllerConfigLoader is implemented by configuration loaders which also provide controller configuration.
+// Controller configuration of other loaders has default values.
+type ControllerConfigLoader interface {
 	ConfigLoader
+	GetControllerConfig() (*ControllerConfig, error)
+}
+
+type EnvConfigLoader interface {
+	ControllerConfigLoader
 	getRequiredEnv(string) (string, error)
 	getServicesEnv(string, ...string) (map[string][]string, error)
+	getOptionalPathEnv(string) []string
 }
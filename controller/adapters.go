@@ -0,0 +1,152 @@
+// Copyright 2024-2025 NetCracker Technology Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Netcracker/qubership-disaster-recovery-daemon/api/entity"
+)
+
+const (
+	maxOutputLength        = 4096
+	minAdapterRequeueDelay = time.Second
+	// command is waited less than default grace period after it is killed, so DR function returns before it is abandoned
+	commandWaitDelay = 5 * time.Second
+)
+
+// adapterResponse is controller response in the wire format of command and webhook adapters. RequeueAfter is
+// a number of seconds, since JSON has no duration type.
+type adapterResponse struct {
+	entity.SwitchoverState
+	InProgress   bool    `json:"inProgress,omitempty"`
+	RequeueAfter float64 `json:"requeueAfter,omitempty"`
+}
+
+// CommandFunc returns DR function which executes the command with the given arguments. Controller request
+// is passed as JSON to stdin, and controller response is read as JSON from stdout. Non-zero exit code is treated
+// as an error, so the command is retried according to the retry policy. When the context is cancelled, the whole
+// process group of the command is killed, so child processes of shell scripts do not outlive the switchover.
+func CommandFunc(name string, args ...string) func(ctx context.Context, request entity.ControllerRequest) (entity.ControllerResponse, error) {
+	return func(ctx context.Context, request entity.ControllerRequest) (entity.ControllerResponse, error) {
+		input, err := json.Marshal(request)
+		if err != nil {
+			return entity.ControllerResponse{}, Permanent(fmt.Errorf("cannot encode controller request: %w", err))
+		}
+		var stdout, stderr bytes.Buffer
+		command := exec.CommandContext(ctx, name, args...)
+		command.Stdin = bytes.NewReader(input)
+		command.Stdout = &stdout
+		command.Stderr = &stderr
+		command.WaitDelay = commandWaitDelay
+		killProcessGroupOnCancel(command)
+		log.Printf("Executing DR command '%s'", name)
+		if err := command.Run(); err != nil {
+			return entity.ControllerResponse{}, fmt.Errorf("DR command '%s' failed: %w: %s", name, err, truncate(stderr.String()))
+		}
+		return decodeResponse(stdout.Bytes(), fmt.Sprintf("DR command '%s'", name))
+	}
+}
+
+// WebhookFunc returns DR function which sends controller request as JSON in POST request to the URL and reads
+// controller response as JSON from response body. The headers are added to every request, e.g. for authorization.
+// 4xx response codes are treated as permanent errors, other non-2xx codes are retried according to the retry
+// policy, Retry-After header of 429 and 503 responses is respected.
+func WebhookFunc(url string, headers map[string]string) func(ctx context.Context, request entity.ControllerRequest) (entity.ControllerResponse, error) {
+	return func(ctx context.Context, request entity.ControllerRequest) (entity.ControllerResponse, error) {
+		input, err := json.Marshal(request)
+		if err != nil {
+			return entity.ControllerResponse{}, Permanent(fmt.Errorf("cannot encode controller request: %w", err))
+		}
+		httpRequest, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(input))
+		if err != nil {
+			return entity.ControllerResponse{}, Permanent(err)
+		}
+		httpRequest.Header.Set("Content-Type", "application/json")
+		for name, value := range headers {
+			httpRequest.Header.Set(name, value)
+		}
+		log.Printf("Calling DR webhook '%s'", url)
+		httpResponse, err := http.DefaultClient.Do(httpRequest)
+		if err != nil {
+			return entity.ControllerResponse{}, fmt.Errorf("DR webhook '%s' is not available: %w", url, err)
+		}
+		defer httpResponse.Body.Close()
+		body, err := io.ReadAll(httpResponse.Body)
+		if err != nil {
+			return entity.ControllerResponse{}, fmt.Errorf("cannot read response of DR webhook '%s': %w", url, err)
+		}
+		if httpResponse.StatusCode/100 != 2 {
+			err = fmt.Errorf("DR webhook '%s' returned status %d: %s", url, httpResponse.StatusCode, truncate(string(body)))
+			return entity.ControllerResponse{}, webhookError(httpResponse, err)
+		}
+		return decodeResponse(body, fmt.Sprintf("DR webhook '%s'", url))
+	}
+}
+
+func webhookError(response *http.Response, err error) error {
+	switch response.StatusCode {
+	case http.StatusTooManyRequests, http.StatusServiceUnavailable:
+		if seconds, parseErr := strconv.Atoi(response.Header.Get("Retry-After")); parseErr == nil && seconds > 0 {
+			return RetryAfter(err, time.Duration(seconds)*time.Second)
+		}
+		return err
+	}
+	if response.StatusCode/100 == 4 {
+		return Permanent(err)
+	}
+	return err
+}
+
+// decodeResponse reads controller response in adapter wire format, positive requeue delay is not less than a second.
+func decodeResponse(output []byte, source string) (entity.ControllerResponse, error) {
+	var response adapterResponse
+	decoder := json.NewDecoder(bytes.NewReader(output))
+	if err := decoder.Decode(&response); err != nil {
+		return entity.ControllerResponse{}, Permanent(fmt.Errorf("%s returned invalid controller response: %w: %s",
+			source, err, truncate(string(output))))
+	}
+	if response.Status == "" && !response.InProgress && response.RequeueAfter <= 0 {
+		return entity.ControllerResponse{}, Permanent(errors.New(source + " returned controller response without status"))
+	}
+	var requeueAfter time.Duration
+	if response.RequeueAfter > 0 {
+		requeueAfter = max(time.Duration(response.RequeueAfter*float64(time.Second)), minAdapterRequeueDelay)
+	}
+	return entity.ControllerResponse{
+		SwitchoverState: response.SwitchoverState,
+		InProgress:      response.InProgress,
+		RequeueAfter:    requeueAfter,
+	}, nil
+}
+
+func truncate(output string) string {
+	output = strings.TrimSpace(output)
+	if len(output) > maxOutputLength {
+		return output[:maxOutputLength] + "..."
+	}
+	return output
+}
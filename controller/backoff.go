@@ -0,0 +1,67 @@
+// Copyright 2024-2025 NetCracker Technology Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"math"
+	"math/rand/v2"
+	"sync"
+	"time"
+)
+
+// backoffJitter is the maximum part of the delay which is randomly added to it
+const backoffJitter = 0.2
+
+// jitterBackoffRateLimiter doubles delay for every next failure of the item and adds random jitter to it,
+// so replicas of different services do not retry at the same moment.
+type jitterBackoffRateLimiter struct {
+	baseDelay time.Duration
+	maxDelay  time.Duration
+	failures  map[string]int
+	mutex     sync.Mutex
+}
+
+func newJitterBackoffRateLimiter(baseDelay time.Duration, maxDelay time.Duration) *jitterBackoffRateLimiter {
+	return &jitterBackoffRateLimiter{
+		baseDelay: baseDelay,
+		maxDelay:  maxDelay,
+		failures:  map[string]int{},
+	}
+}
+
+func (rl *jitterBackoffRateLimiter) When(item string) time.Duration {
+	rl.mutex.Lock()
+	defer rl.mutex.Unlock()
+	exp := rl.failures[item]
+	rl.failures[item]++
+	delay := float64(rl.baseDelay) * math.Pow(2, float64(exp))
+	delay += delay * backoffJitter * rand.Float64()
+	if delay > float64(rl.maxDelay) {
+		return rl.maxDelay
+	}
+	return time.Duration(delay)
+}
+
+func (rl *jitterBackoffRateLimiter) Forget(item string) {
+	rl.mutex.Lock()
+	defer rl.mutex.Unlock()
+	delete(rl.failures, item)
+}
+
+func (rl *jitterBackoffRateLimiter) NumRequeues(item string) int {
+	rl.mutex.Lock()
+	defer rl.mutex.Unlock()
+	return rl.failures[item]
+}
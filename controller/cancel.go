@@ -0,0 +1,146 @@
+// Copyright 2024-2025 NetCracker Technology Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"encoding/json"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/Netcracker/qubership-disaster-recovery-daemon/api/entity"
+	"github.com/Netcracker/qubership-disaster-recovery-daemon/internal/usecase"
+	"github.com/Netcracker/qubership-disaster-recovery-daemon/metrics"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/tools/cache"
+)
+
+// handleCancel cancels queued and running switchovers if DR resource contains cancel request which has not been
+// handled yet. Cancel request which already exists when DR resource is added applies only to the queued or running
+// switchover which started before the request, so the request handled before controller restart does not cancel
+// the next switchover. If nothing is held in memory, e.g. the switchover was queued before controller restart,
+// the switchover is cancelled by its status.
+func (ctr *Controller) handleCancel(resource *unstructured.Unstructured, controllerRequest entity.ControllerRequest,
+	eventType watch.EventType) {
+	cancelRequest, ok := getCancelRequest(resource.Object)
+	if !ok {
+		return
+	}
+	ctr.mutex.Lock()
+	if cancelRequest.ID == ctr.lastCancelID {
+		ctr.mutex.Unlock()
+		return
+	}
+	ctr.lastCancelID = cancelRequest.ID
+	if eventType == watch.Added && !ctr.isPendingCancel(cancelRequest, controllerRequest) {
+		ctr.mutex.Unlock()
+		return
+	}
+	var keys []string
+	for key, request := range ctr.requests {
+		if request.controllerRequest.DryRun {
+			continue
+		}
+		request.cancelledBy = cancelRequest.Caller
+		ctr.requests[key] = request
+		keys = append(keys, key)
+	}
+	cancelRunning := ctr.cancelRunning
+	if len(keys) == 0 && cancelRunning == nil && !controllerRequest.DryRun &&
+		(controllerRequest.Status.Status == entity.QUEUE || controllerRequest.Status.Status == entity.RUNNING) {
+		key, err := cache.MetaNamespaceKeyFunc(resource)
+		if err != nil {
+			ctr.mutex.Unlock()
+			log.Printf("Cannot build key for DR resource: %v", err)
+			return
+		}
+		ctr.sequence++
+		ctr.requests[key] = queuedRequest{
+			controllerRequest: controllerRequest,
+			eventType:         eventType,
+			sequence:          ctr.sequence,
+			cancelledBy:       cancelRequest.Caller,
+		}
+		keys = append(keys, key)
+	}
+	ctr.mutex.Unlock()
+
+	log.Printf("Switchover cancellation is requested by %s", cancelRequest.Caller)
+	if cancelRunning != nil {
+		cancelRunning(&CancelledError{Caller: cancelRequest.Caller})
+	}
+	// switchover which waits for retry or requeue is cancelled without waiting for the delay
+	for _, key := range keys {
+		ctr.queue.Add(key)
+	}
+}
+
+// cancelSwitchover marks the switchover as failed with the caller who cancelled it. Rollback function is not called,
+// since the caller has explicitly stopped the switchover.
+func (ctr *Controller) cancelSwitchover(request queuedRequest, cancelledErr *CancelledError) {
+	log.Printf("Switchover to '%s' mode is %v", request.controllerRequest.Mode, cancelledErr)
+	state := entity.SwitchoverState{
+		Mode:    request.controllerRequest.Mode,
+		Status:  entity.FAILED,
+		Comment: cancelledErr.Error(),
+	}
+	err := ctr.crKubernetesRepo.UpdateStatus(ctr.config.DisasterRecoveryStatusPath, state,
+		entity.StatusField{Path: ctr.config.DisasterRecoveryStatusPath.RolledBackPath, Value: false})
+	if err != nil {
+		log.Printf("Error: Cannot update resource status due to: %v", err)
+	}
+	if err := ctr.updateResourceVersion(ctr.crKubernetesRepo); err != nil {
+		log.Printf("Error: Cannot update resource status due to: %v", err)
+	}
+	metrics.SetState(state)
+	if !request.started.IsZero() {
+		metrics.ObserveSwitchover(state.Mode, entity.FAILED, time.Since(request.started))
+	}
+	ctr.recordEvent(request.controllerRequest.Object, corev1.EventTypeWarning, entity.SwitchoverCancelledReason,
+		"Switchover to '%s' mode was %v", state.Mode, cancelledErr)
+}
+
+// isPendingCancel checks whether cancel request is written for the switchover which is still queued or running.
+// Start time of the switchover is stored with seconds precision, so the request time is truncated as well.
+func (ctr *Controller) isPendingCancel(cancelRequest usecase.CancelRequest, controllerRequest entity.ControllerRequest) bool {
+	if controllerRequest.Status.Status != entity.QUEUE && controllerRequest.Status.Status != entity.RUNNING {
+		return false
+	}
+	startTime, err := time.Parse(time.RFC3339,
+		nestedString(controllerRequest.Object, ctr.config.DisasterRecoveryStatusPath.StartTimePath))
+	if err != nil {
+		return true
+	}
+	requested, err := strconv.ParseInt(cancelRequest.ID, 10, 64)
+	if err != nil {
+		return true
+	}
+	return !time.Unix(0, requested).Truncate(time.Second).Before(startTime)
+}
+
+func getCancelRequest(object map[string]interface{}) (usecase.CancelRequest, bool) {
+	var cancelRequest usecase.CancelRequest
+	annotation, _, _ := unstructured.NestedString(object, "metadata", "annotations", usecase.CancelAnnotationKey)
+	if annotation == "" {
+		return cancelRequest, false
+	}
+	if err := json.Unmarshal([]byte(annotation), &cancelRequest); err != nil {
+		log.Printf("Cannot deserialize cancel request: %v", err)
+		return cancelRequest, false
+	}
+	return cancelRequest, cancelRequest.ID != ""
+}
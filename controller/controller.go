@@ -16,9 +16,14 @@ package controller
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"log"
+	"os"
 	"reflect"
 	"strconv"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/Netcracker/qubership-disaster-recovery-daemon/api/entity"
@@ -26,32 +31,95 @@ import (
 	"github.com/Netcracker/qubership-disaster-recovery-daemon/config"
 	"github.com/Netcracker/qubership-disaster-recovery-daemon/internal/usecase"
 	"github.com/Netcracker/qubership-disaster-recovery-daemon/internal/usecase/repo"
-	"github.com/avast/retry-go/v4"
+	"github.com/Netcracker/qubership-disaster-recovery-daemon/metrics"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
 )
 
 var SwitchoverAnnotationKeyPath = []string{"metadata", "annotations", usecase.SwitchoverAnnotationKey}
 
+const (
+	interruptedComment  = "Switchover was interrupted because DR controller is shutting down"
+	inProgressComment   = "Switchover is in progress"
+	maxRetryDelay       = 5 * time.Minute
+	defaultRequeueDelay = 10 * time.Second
+	defaultGracePeriod  = 10 * time.Second
+	maxPostponements    = 10
+	funcRunningComment  = "Previous DR function is still running after its context was cancelled"
+)
+
 type Controller struct {
-	controllerFunc   func(request entity.ControllerRequest) (entity.ControllerResponse, error)
+	controllerFunc   func(ctx context.Context, request entity.ControllerRequest) (entity.ControllerResponse, error)
+	rollbackFunc     func(request entity.ControllerRequest, err error) (entity.ControllerResponse, error)
+	deleteFunc       func(request entity.ControllerRequest)
+	dryRunFunc       func(ctx context.Context, request entity.ControllerRequest) (entity.DryRunResult, error)
+	dryRunning       atomic.Bool
+	dryRuns          sync.WaitGroup
+	lastDryRunID     string
+	lastCancelID     string
+	cancelRunning    context.CancelCauseFunc
 	config           *config.Config
 	resourceVersion  string
 	delay            time.Duration
 	attempts         uint
+	maxDelay         time.Duration
+	maxElapsedTime   time.Duration
+	timeout          time.Duration
+	gracePeriod      time.Duration
+	funcRunning      atomic.Bool
+	stalePolicy      string
+	identity         string
+	recoveryPending  atomic.Bool
+	recoveryStarted  atomic.Int64
+	dynClient        dynamic.Interface
+	kubeClient       kubernetes.Interface
 	crKubernetesRepo usecase.KubernetesCustomResourceRepo
+	eventRecorder    usecase.EventRecorder
+	leaderElection   *leaderElectionConfig
+	leader           atomic.Bool
+	queue            workqueue.TypedRateLimitingInterface[string]
+	requests         map[string]queuedRequest
+	sequence         uint64
+	mutex            sync.Mutex
+}
+
+type queuedRequest struct {
+	controllerRequest entity.ControllerRequest
+	eventType         watch.EventType
+	sequence          uint64
+	attempt           int
+	failures          uint
+	started           time.Time
+	inProgress        bool
+	cancelledBy       string
+	postponed         int
 }
 
 func NewController(config *config.Config) *Controller {
-	return &Controller{config: config, delay: time.Second * 5, attempts: 1}
+	return &Controller{config: config, delay: time.Second * 5, attempts: 1, maxDelay: maxRetryDelay, timeout: config.SwitchoverTimeout,
+		gracePeriod: defaultGracePeriod, stalePolicy: config.StaleSwitchoverPolicy, requests: map[string]queuedRequest{}}
 }
 
+// WithFunc sets DR function which does not need to know about controller cancellation.
 func (ctr *Controller) WithFunc(controllerFunc func(request entity.ControllerRequest) (entity.ControllerResponse, error)) *Controller {
+	ctr.controllerFunc = func(_ context.Context, request entity.ControllerRequest) (entity.ControllerResponse, error) {
+		return controllerFunc(request)
+	}
+	return ctr
+}
+
+// WithContextFunc sets DR function which receives context. The context is cancelled when controller is stopping,
+// so the function should interrupt switchover and return as soon as possible.
+func (ctr *Controller) WithContextFunc(controllerFunc func(ctx context.Context, request entity.ControllerRequest) (entity.ControllerResponse, error)) *Controller {
 	ctr.controllerFunc = controllerFunc
 	return ctr
 }
@@ -62,10 +130,46 @@ func (ctr *Controller) WithRetry(attempts uint, delay time.Duration) *Controller
 	return ctr
 }
 
-func (ctr *Controller) Run() {
+// WithBackoff limits retry delay which is doubled for every next attempt, and the time since switchover start
+// after which no more retries are performed. Zero maxElapsedTime means that only number of attempts is limited.
+func (ctr *Controller) WithBackoff(maxDelay time.Duration, maxElapsedTime time.Duration) *Controller {
+	ctr.maxDelay = maxDelay
+	ctr.maxElapsedTime = maxElapsedTime
+	return ctr
+}
+
+// WithTimeout limits duration of DR function execution. When timeout is exceeded, function context is cancelled
+// and switchover is marked as failed without retries. Zero timeout means no limit.
+func (ctr *Controller) WithTimeout(timeout time.Duration) *Controller {
+	ctr.timeout = timeout
+	return ctr
+}
+
+// WithGracePeriod sets how long controller waits for DR function to return after its context is cancelled,
+// e.g. on timeout. If the function does not return, the next switchover is postponed until it does, and it is
+// marked as failed after 10 postponements. The default grace period is 10 seconds.
+func (ctr *Controller) WithGracePeriod(gracePeriod time.Duration) *Controller {
+	ctr.gracePeriod = gracePeriod
+	return ctr
+}
+
+// WithDynamicClient sets client which is used to watch and update DR resource instead of client built from the environment.
+func (ctr *Controller) WithDynamicClient(dynClient dynamic.Interface) *Controller {
+	ctr.dynClient = dynClient
+	return ctr
+}
+
+// WithKubeClient sets client which is used for Kubernetes Events and leader election instead of client built from the environment.
+func (ctr *Controller) WithKubeClient(kubeClient kubernetes.Interface) *Controller {
+	ctr.kubeClient = kubeClient
+	return ctr
+}
 
+// Run starts watching DR resource and blocks until ctx is cancelled. In-flight switchover receives
+// cancelled context and its status is set to failed before Run returns.
+func (ctr *Controller) Run(ctx context.Context) error {
 	if ctr.controllerFunc == nil {
-		log.Panic("Unable to run controller without controller function")
+		return errors.New("unable to run controller without controller function")
 	}
 
 	resource := schema.GroupVersionResource{
@@ -74,45 +178,82 @@ func (ctr *Controller) Run() {
 		Resource: ctr.config.Resource,
 	}
 
-	dynClient := client.MakeDynamicClient()
+	if ctr.dynClient == nil {
+		ctr.dynClient = client.MakeDynamicClient()
+	}
+	if ctr.kubeClient == nil {
+		ctr.kubeClient = client.MakeKubeClientSet()
+	}
+	dynClient := ctr.dynClient
+	kubeClient := ctr.kubeClient
 	ctr.crKubernetesRepo = repo.NewKubernetesCustomResourceRepo(dynClient, resource, ctr.config.Name, ctr.config.Namespace)
+	// events are still recorded when in-flight switchover is failed on shutdown, so recorder is stopped when Run returns
+	recorderCtx, stopRecorder := context.WithCancel(context.WithoutCancel(ctx))
+	defer stopRecorder()
+	ctr.eventRecorder = repo.NewKubernetesEventRecorder(recorderCtx, kubeClient, "disaster-recovery-controller")
 
+	// fake clients do not support streaming list, so reflector must know the client to fall back to list and watch
 	informer := cache.NewSharedIndexInformer(
-		&cache.ListWatch{
+		cache.ToListWatcherWithWatchListSemantics(&cache.ListWatch{
 			ListFunc: func(opts metav1.ListOptions) (runtime.Object, error) {
-				return dynClient.Resource(resource).Namespace(ctr.config.Namespace).List(context.TODO(), metav1.ListOptions{
+				return dynClient.Resource(resource).Namespace(ctr.config.Namespace).List(ctx, metav1.ListOptions{
 					FieldSelector: fields.OneTermEqualSelector("metadata.name", ctr.config.CustomResourceConfig.Name).String(),
 				})
 			},
 			WatchFunc: func(opts metav1.ListOptions) (watch.Interface, error) {
-				return dynClient.Resource(resource).Namespace(ctr.config.Namespace).Watch(context.TODO(), metav1.ListOptions{
+				return dynClient.Resource(resource).Namespace(ctr.config.Namespace).Watch(ctx, metav1.ListOptions{
 					FieldSelector: fields.OneTermEqualSelector("metadata.name", ctr.config.CustomResourceConfig.Name).String(),
 				})
 			},
-		},
+		}, dynClient),
 		&unstructured.Unstructured{},
 		1*time.Hour,
 		cache.Indexers{},
 	)
 
 	log.Printf("Controller initiating")
-	_, err := informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+	identity, err := os.Hostname()
+	if err != nil {
+		return fmt.Errorf("cannot obtain controller identity: %w", err)
+	}
+	ctr.identity = identity
+	ctr.queue = ctr.newQueue()
+	// the first processed state of DR resource is checked for switchover interrupted by controller restart
+	ctr.startRecovery()
+	_, err = informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
 		UpdateFunc: func(old, new interface{}) {
 			ctr.handleEvent(old, new, watch.Modified)
 		},
 		AddFunc: func(obj interface{}) {
 			ctr.handleEvent(nil, obj, watch.Added)
 		},
+		DeleteFunc: func(obj interface{}) {
+			ctr.handleDelete(obj)
+		},
 	})
 	if err != nil {
-		log.Panicf("Cannot register event handler function: %v", err)
-		return
+		return fmt.Errorf("cannot register event handler function: %w", err)
+	}
+	if ctr.leaderElection != nil {
+		elector, err := ctr.buildLeaderElector(kubeClient, informer)
+		if err != nil {
+			return fmt.Errorf("cannot configure leader election: %w", err)
+		}
+		go ctr.runLeaderElector(ctx, elector)
 	}
-	stopCh := make(chan struct{})
-	defer close(stopCh)
+	workerDone := make(chan struct{})
+	go func() {
+		defer close(workerDone)
+		ctr.runWorker(ctx)
+	}()
 	log.Printf("Controller started")
-	informer.Run(stopCh)
+	informer.Run(ctx.Done())
+	ctr.queue.ShutDown()
+	// wait for in-flight switchover, so its final status is written before controller exits
+	<-workerDone
+	ctr.dryRuns.Wait()
 	log.Printf("Controller finished")
+	return nil
 }
 
 func (ctr *Controller) handleEvent(old interface{}, new interface{}, eventType watch.EventType) {
@@ -120,12 +261,20 @@ func (ctr *Controller) handleEvent(old interface{}, new interface{}, eventType w
 		log.Printf("DR resource is null")
 		return
 	}
+	if !ctr.isLeader() {
+		log.Printf("Skip event. DR controller is not a leader")
+		return
+	}
 	newResource := new.(*unstructured.Unstructured)
 	controllerRequestNew, err := buildControllerRequest(newResource.UnstructuredContent(), ctr.config)
 	if err != nil {
 		log.Printf("Cannot deserialize DR resource and build DR request: %v", err)
 		return
 	}
+	ctr.handleDryRun(newResource, controllerRequestNew)
+	if eventType != watch.Added {
+		ctr.handleCancel(newResource, controllerRequestNew, eventType)
+	}
 
 	if old != nil {
 		oldResource := old.(*unstructured.Unstructured)
@@ -139,6 +288,8 @@ func (ctr *Controller) handleEvent(old interface{}, new interface{}, eventType w
 			return
 		}
 
+		setPreviousState(&controllerRequestNew, controllerRequestOld)
+
 		if controllerRequestNew.Mode == controllerRequestOld.Mode &&
 			controllerRequestNew.SwitchoverAnnotation == controllerRequestOld.SwitchoverAnnotation {
 			log.Printf("Skip event. Old and New resourses are the same: DR mode '%s', switchoverRetry '%s', state: '%+v'",
@@ -147,81 +298,485 @@ func (ctr *Controller) handleEvent(old interface{}, new interface{}, eventType w
 		}
 	}
 
-	err = retry.Do(func() error {
-		return ctr.executeDrFunction(controllerRequestNew, eventType)
-	},
-		retry.Delay(ctr.delay), retry.Attempts(ctr.attempts), retry.OnRetry(func(n uint, err error) {
-			ctr.resourceVersion = ""
-		}))
+	key, err := cache.MetaNamespaceKeyFunc(newResource)
+	if err != nil {
+		log.Printf("Cannot build key for DR resource: %v", err)
+		return
+	}
+	ctr.enqueue(key, controllerRequestNew, eventType)
+	if eventType == watch.Added {
+		// cancel request written while controller was down applies to the switchover which is resumed
+		ctr.handleCancel(newResource, controllerRequestNew, eventType)
+	}
+}
+
+// enqueue stores the latest request for the key, so repeated events which are not processed yet collapse into one reconcile.
+// The collapsed request is compared with the state of DR resource before the first of collapsed events.
+func (ctr *Controller) enqueue(key string, controllerRequest entity.ControllerRequest, eventType watch.EventType) {
+	ctr.mutex.Lock()
+	if pending, ok := ctr.requests[key]; ok {
+		ctr.keepPreviousState(&controllerRequest, pending, eventType)
+	}
+	ctr.sequence++
+	ctr.requests[key] = queuedRequest{
+		controllerRequest: controllerRequest,
+		eventType:         eventType,
+		sequence:          ctr.sequence,
+	}
+	ctr.mutex.Unlock()
+	// new request must not inherit backoff of the previous one
+	ctr.queue.Forget(key)
+	ctr.queue.Add(key)
+}
+
+func (ctr *Controller) runWorker(ctx context.Context) {
+	for ctr.processNextItem(ctx) {
+	}
+}
+
+func (ctr *Controller) processNextItem(ctx context.Context) bool {
+	key, shutdown := ctr.queue.Get()
+	if shutdown {
+		return false
+	}
+	defer ctr.queue.Done(key)
+	ctr.reconcile(ctx, key)
+	return true
+}
+
+func (ctr *Controller) reconcile(ctx context.Context, key string) {
+	ctr.mutex.Lock()
+	request, ok := ctr.requests[key]
+	ctr.mutex.Unlock()
+	if !ok {
+		ctr.queue.Forget(key)
+		return
+	}
+	if ctx.Err() != nil {
+		log.Printf("Skip event. DR controller is shutting down")
+		ctr.complete(key, request)
+		return
+	}
+	if !ctr.isLeader() {
+		log.Printf("Skip event. DR controller is not a leader")
+		ctr.complete(key, request)
+		return
+	}
+
+	if request.controllerRequest.DryRun {
+		ctr.startDryRun(ctx, key, request)
+		return
+	}
+
+	if request.eventType == watch.Deleted {
+		ctr.handleDeletion(request.controllerRequest)
+		ctr.complete(key, request)
+		return
+	}
+
+	if ctr.recoveryPending.Swap(false) && ctr.failStaleSwitchover(request.controllerRequest) {
+		ctr.complete(key, request)
+		return
+	}
+
+	if request.cancelledBy != "" {
+		ctr.cancelSwitchover(request, &CancelledError{Caller: request.cancelledBy})
+		ctr.complete(key, request)
+		return
+	}
+
+	if ctr.funcRunning.Load() {
+		if request.postponed >= maxPostponements {
+			ctr.failSwitchover(request, funcRunningComment)
+			ctr.complete(key, request)
+			return
+		}
+		log.Printf("Switchover to '%s' mode is postponed (%d of %d), previous DR function has not returned yet",
+			request.controllerRequest.Mode, request.postponed+1, maxPostponements)
+		ctr.postpone(key, request)
+		return
+	}
+
+	request = ctr.startAttempt(key, request)
+	requeueAfter, err := ctr.executeDrFunction(ctx, request)
+	if err == nil {
+		if requeueAfter > 0 {
+			ctr.requeue(key, request, requeueAfter)
+			return
+		}
+		ctr.complete(key, request)
+		return
+	}
+	if errors.Is(err, ErrLeadershipLost) || !ctr.isLeader() {
+		log.Printf("Switchover to '%s' mode is left to the new leader: %v", request.controllerRequest.Mode, err)
+		ctr.complete(key, request)
+		return
+	}
+	if ctr.isRetryNeeded(ctx, request, err) {
+		ctr.retry(key, request, err)
+		return
+	}
 
+	var cancelledErr *CancelledError
+	if errors.As(err, &cancelledErr) && ctx.Err() == nil {
+		ctr.cancelSwitchover(request, cancelledErr)
+		ctr.complete(key, request)
+		return
+	}
+
+	log.Printf("Error occurred during performing DR controller function: %v", err)
+	comment := err.Error()
+	rolledBack := false
+	var notCalledErr *notCalledError
+	if ctx.Err() != nil {
+		comment = fmt.Sprintf("%s: %v", interruptedComment, err)
+	} else if ctr.rollbackFunc != nil && !errors.As(err, &notCalledErr) {
+		comment, rolledBack = ctr.rollback(request.controllerRequest, err, comment)
+	}
+	controllerResponse := entity.ControllerResponse{
+		SwitchoverState: entity.SwitchoverState{
+			Mode:    request.controllerRequest.Mode,
+			Status:  entity.FAILED,
+			Comment: comment,
+		},
+	}
+	err = ctr.crKubernetesRepo.UpdateStatus(ctr.config.DisasterRecoveryStatusPath, controllerResponse.SwitchoverState,
+		entity.StatusField{Path: ctr.config.DisasterRecoveryStatusPath.RolledBackPath, Value: rolledBack})
 	if err != nil {
-		log.Printf("Error occurred during performing DR controller function: %v", err)
-		controllerResponse := entity.ControllerResponse{
-			SwitchoverState: entity.SwitchoverState{
-				Mode:    controllerRequestNew.Mode,
-				Status:  entity.FAILED,
-				Comment: err.Error(),
-			},
+		log.Printf("Error: Cannot update resource status due to: %v", err)
+	}
+	if err := ctr.updateResourceVersion(ctr.crKubernetesRepo); err != nil {
+		log.Printf("Error: Cannot update resource status due to: %v", err)
+	}
+	metrics.SetState(controllerResponse.SwitchoverState)
+	metrics.ObserveSwitchover(request.controllerRequest.Mode, entity.FAILED, time.Since(request.started))
+	ctr.recordEvent(request.controllerRequest.Object, corev1.EventTypeWarning, entity.SwitchoverFailedReason,
+		"Switchover to '%s' mode failed: %s", request.controllerRequest.Mode, comment)
+	ctr.complete(key, request)
+}
+
+// startAttempt counts DR function invocations for the request and remembers when its switchover has started.
+func (ctr *Controller) startAttempt(key string, request queuedRequest) queuedRequest {
+	request.attempt++
+	if request.started.IsZero() {
+		request.started = time.Now()
+	}
+	ctr.mutex.Lock()
+	defer ctr.mutex.Unlock()
+	if current, ok := ctr.requests[key]; ok && current.sequence == request.sequence {
+		ctr.requests[key] = request
+	}
+	return request
+}
+
+func (ctr *Controller) isRetryNeeded(ctx context.Context, request queuedRequest, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	var timeoutErr *TimeoutError
+	var permanentErr *PermanentError
+	var cancelledErr *CancelledError
+	if errors.As(err, &timeoutErr) || errors.As(err, &permanentErr) || errors.As(err, &cancelledErr) {
+		return false
+	}
+	if request.failures+1 >= ctr.attempts {
+		return false
+	}
+	return ctr.maxElapsedTime <= 0 || time.Since(request.started) < ctr.maxElapsedTime
+}
+
+// retry schedules the next attempt after backoff delay or after the delay requested by DR function.
+func (ctr *Controller) retry(key string, request queuedRequest, err error) {
+	ctr.mutex.Lock()
+	if current, ok := ctr.requests[key]; ok && current.sequence == request.sequence {
+		current.failures++
+		ctr.requests[key] = current
+	}
+	ctr.mutex.Unlock()
+	ctr.resourceVersion = ""
+	metrics.ObserveRetry(request.controllerRequest.Mode)
+	ctr.recordEvent(request.controllerRequest.Object, corev1.EventTypeWarning, entity.SwitchoverRetriedReason,
+		"Attempt %d of switchover to '%s' mode failed, switchover will be retried: %v", request.failures+1, request.controllerRequest.Mode, err)
+	var retryAfterErr *RetryAfterError
+	if errors.As(err, &retryAfterErr) {
+		log.Printf("Attempt %d of DR controller function failed, retry will be performed in %v: %v",
+			request.failures+1, retryAfterErr.Delay, err)
+		ctr.queue.AddAfter(key, retryAfterErr.Delay)
+		return
+	}
+	log.Printf("Attempt %d of DR controller function failed, retry will be performed: %v", request.failures+1, err)
+	ctr.queue.AddRateLimited(key)
+}
+
+// requeue schedules the next call of DR function for switchover which is still in progress.
+func (ctr *Controller) requeue(key string, request queuedRequest, requeueAfter time.Duration) {
+	ctr.mutex.Lock()
+	if current, ok := ctr.requests[key]; ok && current.sequence == request.sequence {
+		request.inProgress = true
+		ctr.requests[key] = request
+	}
+	ctr.mutex.Unlock()
+	ctr.queue.Forget(key)
+	ctr.queue.AddAfter(key, requeueAfter)
+}
+
+// postpone checks the request again after the retry delay without counting it as an attempt.
+func (ctr *Controller) postpone(key string, request queuedRequest) {
+	ctr.mutex.Lock()
+	if current, ok := ctr.requests[key]; ok && current.sequence == request.sequence {
+		current.postponed++
+		ctr.requests[key] = current
+	}
+	ctr.mutex.Unlock()
+	ctr.queue.AddAfter(key, ctr.delay)
+}
+
+// failSwitchover marks the switchover as failed without calling DR function and rollback function.
+func (ctr *Controller) failSwitchover(request queuedRequest, comment string) {
+	log.Printf("Switchover to '%s' mode failed: %s", request.controllerRequest.Mode, comment)
+	state := entity.SwitchoverState{
+		Mode:    request.controllerRequest.Mode,
+		Status:  entity.FAILED,
+		Comment: comment,
+	}
+	err := ctr.crKubernetesRepo.UpdateStatus(ctr.config.DisasterRecoveryStatusPath, state,
+		entity.StatusField{Path: ctr.config.DisasterRecoveryStatusPath.RolledBackPath, Value: false})
+	if err != nil {
+		log.Printf("Error: Cannot update resource status due to: %v", err)
+	}
+	if err := ctr.updateResourceVersion(ctr.crKubernetesRepo); err != nil {
+		log.Printf("Error: Cannot update resource status due to: %v", err)
+	}
+	metrics.SetState(state)
+	ctr.recordEvent(request.controllerRequest.Object, corev1.EventTypeWarning, entity.SwitchoverFailedReason,
+		"Switchover to '%s' mode failed: %s", state.Mode, comment)
+}
+
+// complete removes processed request unless a newer one has been received during processing.
+func (ctr *Controller) complete(key string, request queuedRequest) {
+	ctr.queue.Forget(key)
+	ctr.mutex.Lock()
+	defer ctr.mutex.Unlock()
+	if current, ok := ctr.requests[key]; ok && current.sequence == request.sequence {
+		delete(ctr.requests, key)
+	}
+}
+
+func (ctr *Controller) newQueue() workqueue.TypedRateLimitingInterface[string] {
+	return workqueue.NewTypedRateLimitingQueueWithConfig(
+		newJitterBackoffRateLimiter(ctr.delay, ctr.maxDelay),
+		workqueue.TypedRateLimitingQueueConfig[string]{Name: "dr-controller"})
+}
+
+// executeDrFunction calls DR function and writes its result to DR resource status. It returns positive delay
+// if DR function reported that switchover is still in progress and must be checked again.
+func (ctr *Controller) executeDrFunction(ctx context.Context, request queuedRequest) (time.Duration, error) {
+	controllerRequest := request.controllerRequest
+	if !request.inProgress {
+		resourceVersion, err := ctr.crKubernetesRepo.GetResourceVersion()
+		if err != nil {
+			log.Printf("Cannot obtain resource status version: %v", err)
+			return 0, err
+		}
+		if controllerRequest.Mode == controllerRequest.Status.Mode && controllerRequest.Status.Status == entity.DONE {
+			log.Printf("Current DR mode is already '%s' and finished successfully", controllerRequest.Mode)
+			return 0, nil
+		}
+		if resourceVersion == ctr.resourceVersion {
+			log.Println("Incoming DR resource does not contain changes")
+			return 0, nil
+		}
+		if err := ctr.updateResourceVersion(ctr.crKubernetesRepo); err != nil {
+			return 0, err
+		}
+
+		if !ctr.isLeader() {
+			return 0, ErrLeadershipLost
 		}
-		err = ctr.crKubernetesRepo.UpdateStatus(ctr.config.DisasterRecoveryStatusPath, controllerResponse.SwitchoverState)
+		log.Printf("New incoming DR request with mode '%s', current status is '%s' ", controllerRequest.Mode, controllerRequest.Status.Status)
+		// status write increases the generation if status is treated as a field, so the generation is not reported
+		observedGenerationPath := ctr.config.DisasterRecoveryStatusPath.ObservedGenerationPath
+		if ctr.config.DisasterRecoveryStatusPath.TreatStatusAsField {
+			observedGenerationPath = nil
+		}
+		switchoverState := entity.SwitchoverState{
+			Mode:    controllerRequest.Mode,
+			Status:  entity.RUNNING,
+			Comment: inProgressComment,
+		}
+		err = ctr.crKubernetesRepo.UpdateStatus(ctr.config.DisasterRecoveryStatusPath, switchoverState,
+			entity.StatusField{Path: ctr.config.DisasterRecoveryStatusPath.StartTimePath, Value: request.started.UTC().Format(time.RFC3339)},
+			entity.StatusField{Path: ctr.config.DisasterRecoveryStatusPath.OwnerPath, Value: ctr.identity},
+			entity.StatusField{Path: ctr.config.DisasterRecoveryStatusPath.RolledBackPath, Value: false},
+			entity.StatusField{Path: observedGenerationPath, Value: generation(controllerRequest.Object)})
 		if err != nil {
-			log.Printf("Error: Cannot update resource status due to: %v", err)
+			log.Printf("Cannot update resource status due to: %v", err)
+			return 0, err
 		}
 		if err := ctr.updateResourceVersion(ctr.crKubernetesRepo); err != nil {
-			log.Printf("Error: Cannot update resource status due to: %v", err)
+			return 0, err
 		}
+		metrics.SetState(switchoverState)
+		ctr.recordEvent(controllerRequest.Object, corev1.EventTypeNormal, entity.SwitchoverStartedReason,
+			"Switchover to '%s' mode has started, attempt %d", controllerRequest.Mode, request.attempt)
 	}
-}
 
-func (ctr *Controller) executeDrFunction(controllerRequest entity.ControllerRequest, eventType watch.EventType) error {
-	resourceVersion, err := ctr.crKubernetesRepo.GetResourceVersion()
+	controllerRequest.EventType = request.eventType
+	controllerRequest.Attempt = request.attempt
+	controllerResponse, err := ctr.callControllerFunc(ctx, controllerRequest, request.started)
 	if err != nil {
-		log.Printf("Cannot obtain resource status version: %v", err)
-		return err
+		log.Printf("Error occurred during execution of DR controller function: %v", err)
+		return 0, err
 	}
-	if controllerRequest.Mode == controllerRequest.Status.Mode && controllerRequest.Status.Status == entity.DONE {
-		log.Printf("Current DR mode is already '%s' and finished successfully", controllerRequest.Mode)
-		return nil
+	if controllerResponse.InProgress || controllerResponse.RequeueAfter > 0 {
+		return ctr.continueSwitchover(controllerRequest, controllerResponse)
 	}
-	if resourceVersion == ctr.resourceVersion {
-		log.Println("Incoming DR resource does not contain changes")
-		return nil
+	if !ctr.isLeader() {
+		return 0, ErrLeadershipLost
+	}
+	log.Printf("Switchover finished, status: '%s', comment: '%s'", controllerResponse.Status, controllerResponse.Comment)
+	var fields []entity.StatusField
+	if controllerResponse.Status == entity.FAILED && ctr.rollbackFunc != nil && ctx.Err() == nil {
+		cause := errors.New(controllerResponse.Comment)
+		if controllerResponse.Comment == "" {
+			cause = fmt.Errorf("switchover to '%s' mode failed", controllerRequest.Mode)
+		}
+		var rolledBack bool
+		controllerResponse.Comment, rolledBack = ctr.rollback(controllerRequest, cause, controllerResponse.Comment)
+		fields = append(fields, entity.StatusField{Path: ctr.config.DisasterRecoveryStatusPath.RolledBackPath, Value: rolledBack})
+	}
+	err = ctr.crKubernetesRepo.UpdateStatus(ctr.config.DisasterRecoveryStatusPath, controllerResponse.SwitchoverState, fields...)
+	if err != nil {
+		log.Printf("Cannot update resource status due to: %v", err)
+		return 0, err
 	}
 	if err := ctr.updateResourceVersion(ctr.crKubernetesRepo); err != nil {
-		return err
+		return 0, err
+	}
+	metrics.SetState(controllerResponse.SwitchoverState)
+	metrics.ObserveSwitchover(controllerResponse.Mode, controllerResponse.Status, time.Since(request.started))
+	switch controllerResponse.Status {
+	case entity.DONE:
+		ctr.recordEvent(controllerRequest.Object, corev1.EventTypeNormal, entity.SwitchoverSucceededReason,
+			"Switchover to '%s' mode has finished successfully: %s", controllerResponse.Mode, controllerResponse.Comment)
+	case entity.FAILED:
+		ctr.recordEvent(controllerRequest.Object, corev1.EventTypeWarning, entity.SwitchoverFailedReason,
+			"Switchover to '%s' mode failed: %s", controllerResponse.Mode, controllerResponse.Comment)
 	}
+	return 0, nil
+}
 
-	log.Printf("New incoming DR request with mode '%s', current status is '%s' ", controllerRequest.Mode, controllerRequest.Status.Status)
-	switchoverState := entity.SwitchoverState{
+// continueSwitchover keeps switchover running with the comment from DR function and returns delay before the next call.
+func (ctr *Controller) continueSwitchover(controllerRequest entity.ControllerRequest,
+	controllerResponse entity.ControllerResponse) (time.Duration, error) {
+	requeueAfter := controllerResponse.RequeueAfter
+	if requeueAfter <= 0 {
+		requeueAfter = defaultRequeueDelay
+	}
+	comment := controllerResponse.Comment
+	if comment == "" {
+		comment = inProgressComment
+	}
+	if !ctr.isLeader() {
+		return 0, ErrLeadershipLost
+	}
+	log.Printf("Switchover is still in progress, DR function will be called again in %v, comment: '%s'", requeueAfter, comment)
+	err := ctr.crKubernetesRepo.UpdateStatus(ctr.config.DisasterRecoveryStatusPath, entity.SwitchoverState{
 		Mode:    controllerRequest.Mode,
 		Status:  entity.RUNNING,
-		Comment: "Switchover is in progress",
-	}
-	err = ctr.crKubernetesRepo.UpdateStatus(ctr.config.DisasterRecoveryStatusPath, switchoverState)
+		Comment: comment,
+	})
 	if err != nil {
 		log.Printf("Cannot update resource status due to: %v", err)
-		return err
+		return 0, err
 	}
 	if err := ctr.updateResourceVersion(ctr.crKubernetesRepo); err != nil {
-		return err
+		return 0, err
 	}
+	return requeueAfter, nil
+}
 
-	controllerRequest.EventType = eventType
-	controllerResponse, err := ctr.controllerFunc(controllerRequest)
-	if err != nil {
-		log.Printf("Error occurred during execution of DR controller function: %v", err)
-		return err
+// callControllerFunc runs DR function until it returns or its context is done. The function which ignores
+// cancelled context is left running in background, so hanging function cannot block the controller.
+// Timeout is counted from the switchover start, so it also limits switchover which is requeued or retried.
+func (ctr *Controller) callControllerFunc(ctx context.Context, controllerRequest entity.ControllerRequest,
+	started time.Time) (entity.ControllerResponse, error) {
+	var funcCtx context.Context
+	var cancel context.CancelFunc
+	if ctr.timeout > 0 {
+		timeoutErr := &TimeoutError{Timeout: ctr.timeout}
+		if time.Since(started) >= ctr.timeout {
+			return entity.ControllerResponse{}, timeoutErr
+		}
+		funcCtx, cancel = context.WithDeadlineCause(ctx, started.Add(ctr.timeout), timeoutErr)
+	} else {
+		funcCtx, cancel = context.WithCancel(ctx)
 	}
-	log.Printf("Switchover finished, status: '%s', comment: '%s'", controllerResponse.Status, controllerResponse.Comment)
-	err = ctr.crKubernetesRepo.UpdateStatus(ctr.config.DisasterRecoveryStatusPath, controllerResponse.SwitchoverState)
-	if err != nil {
-		log.Printf("Cannot update resource status due to: %v", err)
-		return err
+	defer cancel()
+	// switchover can be cancelled via DRD server while DR function is running, see handleCancel
+	funcCtx, cancelRunning := context.WithCancelCause(funcCtx)
+	ctr.mutex.Lock()
+	ctr.cancelRunning = cancelRunning
+	ctr.mutex.Unlock()
+	defer func() {
+		ctr.mutex.Lock()
+		ctr.cancelRunning = nil
+		ctr.mutex.Unlock()
+		cancelRunning(nil)
+	}()
+	progress := &statusProgressReporter{
+		ctx:              funcCtx,
+		crKubernetesRepo: ctr.crKubernetesRepo,
+		statusPath:       ctr.config.DisasterRecoveryStatusPath,
+		mode:             controllerRequest.Mode,
 	}
-	if err := ctr.updateResourceVersion(ctr.crKubernetesRepo); err != nil {
-		return err
+	defer progress.finish()
+	controllerRequest.Progress = progress
+
+	type result struct {
+		response entity.ControllerResponse
+		err      error
 	}
-	return nil
+	resultCh := make(chan result, 1)
+	ctr.funcRunning.Store(true)
+	go func() {
+		response, err := ctr.controllerFunc(funcCtx, controllerRequest)
+		ctr.funcRunning.Store(false)
+		resultCh <- result{response: response, err: err}
+	}()
+	var res result
+	select {
+	case res = <-resultCh:
+	case <-funcCtx.Done():
+		// DR function should return soon after its context is cancelled, otherwise the next switchover is
+		// postponed until it does
+		select {
+		case res = <-resultCh:
+		case <-time.After(ctr.gracePeriod):
+			log.Printf("DR controller function has not returned in %v after its context was cancelled, "+
+				"next switchovers are postponed until it returns", ctr.gracePeriod)
+			return entity.ControllerResponse{}, context.Cause(funcCtx)
+		}
+	}
+	if errors.Is(context.Cause(funcCtx), ErrLeadershipLost) {
+		return res.response, ErrLeadershipLost
+	}
+	if res.err != nil && ctx.Err() == nil && funcCtx.Err() != nil {
+		return res.response, context.Cause(funcCtx)
+	}
+	var cancelledErr *CancelledError
+	if errors.As(context.Cause(funcCtx), &cancelledErr) && res.response.Status != entity.DONE {
+		return res.response, cancelledErr
+	}
+	return res.response, res.err
+}
+
+func (ctr *Controller) recordEvent(object map[string]interface{}, eventType string, reason string,
+	messageFormat string, args ...interface{}) {
+	if ctr.eventRecorder == nil {
+		return
+	}
+	ctr.eventRecorder.Event(&unstructured.Unstructured{Object: object}, eventType, reason, fmt.Sprintf(messageFormat, args...))
 }
 
 func (ctr *Controller) updateResourceVersion(crRepo usecase.KubernetesCustomResourceRepo) error {
@@ -289,6 +844,12 @@ func buildControllerRequest(object map[string]interface{}, cfg *config.Config) (
 	return controllerRequest, nil
 }
 
+// generation returns the generation of DR resource object, it is reported as observed when switchover starts.
+func generation(object map[string]interface{}) int64 {
+	value, _, _ := unstructured.NestedInt64(object, "metadata", "generation")
+	return value
+}
+
 func mapsAreEqual(a, b map[string]interface{}) bool {
 	if len(a) != len(b) {
 		return false
@@ -15,14 +15,31 @@
 package controller
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"github.com/Netcracker/qubership-disaster-recovery-daemon/api/entity"
 	repoConfig "github.com/Netcracker/qubership-disaster-recovery-daemon/config"
 	"github.com/Netcracker/qubership-disaster-recovery-daemon/internal/usecase"
+	"github.com/Netcracker/qubership-disaster-recovery-daemon/internal/usecase/repo"
 	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/watch"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+	"k8s.io/client-go/tools/cache"
+	"net/http"
+	"net/http/httptest"
 	"strconv"
+	"sync/atomic"
 	"testing"
 	"time"
 )
@@ -53,29 +70,78 @@ func (t *TestCustomResourceRepo) GetDrMode(...string) (string, error) {
 	return "", nil
 }
 
+func (t *TestCustomResourceRepo) GetResource() (*unstructured.Unstructured, error) {
+	return &unstructured.Unstructured{}, nil
+}
+
 func (t *TestCustomResourceRepo) GetDrStatus(path repoConfig.DisasterRecoveryStatusPath) (entity.SwitchoverState, error) {
 	return t.ResultStatus, nil
 }
 
+func (t *TestCustomResourceRepo) WaitForStatus(ctx context.Context, path repoConfig.DisasterRecoveryStatusPath,
+	condition func(entity.SwitchoverState) bool) (entity.SwitchoverState, error) {
+	return t.ResultStatus, nil
+}
+
+func (t *TestCustomResourceRepo) WaitForObservedGeneration(ctx context.Context, path repoConfig.DisasterRecoveryStatusPath) error {
+	return nil
+}
+
+func (t *TestCustomResourceRepo) WaitForField(ctx context.Context, path []string, condition func(interface{}) (bool, error)) error {
+	return nil
+}
+
 func (t *TestCustomResourceRepo) GetResourceVersion() (string, error) {
 	return strconv.Itoa(t.ResourceVersion), nil
 }
 
+func (t *TestCustomResourceRepo) GetField(...string) (interface{}, bool, error) {
+	return nil, false, nil
+}
+
+func (t *TestCustomResourceRepo) UpdateAnnotations(map[string]string) error {
+	return nil
+}
+
 func (t *TestCustomResourceRepo) UpdateDrMode(repoConfig.DisasterRecoveryPath, entity.ModeDataUpdate) error {
 	return nil
 }
 
-func (t *TestCustomResourceRepo) UpdateStatus(path repoConfig.DisasterRecoveryStatusPath, state entity.SwitchoverState) error {
+func (t *TestCustomResourceRepo) UpdateStatus(path repoConfig.DisasterRecoveryStatusPath, state entity.SwitchoverState, fields ...entity.StatusField) error {
 	t.ResultStatus = state
 	t.ResourceVersion++
 	return nil
 }
 
+func (t *TestCustomResourceRepo) UpdateStatusFields(path repoConfig.DisasterRecoveryStatusPath, fields ...entity.StatusField) error {
+	return nil
+}
+
+func (t *TestCustomResourceRepo) UpdateConditions(path repoConfig.DisasterRecoveryStatusPath, conditions ...metav1.Condition) error {
+	return nil
+}
+
+func (t *TestCustomResourceRepo) AddOperation(path repoConfig.DisasterRecoveryStatusPath, operation entity.Operation) error {
+	return nil
+}
+
+func (t *TestCustomResourceRepo) FailOperation(path repoConfig.DisasterRecoveryStatusPath, id string, comment string) error {
+	return nil
+}
+
+type TestEventRecorder struct {
+	Reasons []string
+}
+
+func (t *TestEventRecorder) Event(object runtime.Object, eventType string, reason string, message string) {
+	t.Reasons = append(t.Reasons, reason)
+}
+
 func TestController_handleNullEvents(t *testing.T) {
 	ctr := buildController(emptyControllerFunc)
-	ctr.handleEvent(nil, nil, watch.Added)
-	ctr.handleEvent(nil, nil, watch.Modified)
-	ctr.handleEvent(nil, nil, watch.Deleted)
+	handleEvent(ctr, context.Background(), nil, nil, watch.Added)
+	handleEvent(ctr, context.Background(), nil, nil, watch.Modified)
+	handleEvent(ctr, context.Background(), nil, nil, watch.Deleted)
 
 	state, _ := ctr.crKubernetesRepo.GetDrStatus(ctr.config.StatusPath)
 	version, _ := ctr.crKubernetesRepo.GetResourceVersion()
@@ -88,20 +154,22 @@ func TestController_handleAddSuccessful(t *testing.T) {
 	ctr := buildController(buildControllerFunc(entity.ACTIVE, entity.DONE, false))
 	newResource := buildCustomResource(entity.ACTIVE, "", "", "")
 
-	ctr.handleEvent(nil, newResource, watch.Added)
+	handleEvent(ctr, context.Background(), nil, newResource, watch.Added)
 
 	state, _ := ctr.crKubernetesRepo.GetDrStatus(ctr.config.StatusPath)
 	version, _ := ctr.crKubernetesRepo.GetResourceVersion()
 	assert.Equalf(t, entity.ACTIVE, state.Mode, "Mode should change")
 	assert.Equalf(t, entity.DONE, state.Status, "Status should change")
 	assert.Equalf(t, "3", version, "Version should change")
+	assert.Equalf(t, []string{entity.SwitchoverStartedReason, entity.SwitchoverSucceededReason},
+		ctr.eventRecorder.(*TestEventRecorder).Reasons, "Events should be recorded")
 }
 
 func TestController_handleAddFailed(t *testing.T) {
 	ctr := buildController(buildControllerFunc(entity.ACTIVE, entity.FAILED, false))
 	newResource := buildCustomResource(entity.ACTIVE, "", "", "")
 
-	ctr.handleEvent(nil, newResource, watch.Added)
+	handleEvent(ctr, context.Background(), nil, newResource, watch.Added)
 
 	state, _ := ctr.crKubernetesRepo.GetDrStatus(ctr.config.StatusPath)
 	version, _ := ctr.crKubernetesRepo.GetResourceVersion()
@@ -114,20 +182,22 @@ func TestController_handleAddError(t *testing.T) {
 	ctr := buildController(buildControllerFunc(entity.ACTIVE, entity.DONE, true))
 	newResource := buildCustomResource(entity.ACTIVE, "", "", "")
 
-	ctr.handleEvent(nil, newResource, watch.Added)
+	handleEvent(ctr, context.Background(), nil, newResource, watch.Added)
 
 	state, _ := ctr.crKubernetesRepo.GetDrStatus(ctr.config.StatusPath)
 	version, _ := ctr.crKubernetesRepo.GetResourceVersion()
 	assert.Equalf(t, entity.ACTIVE, state.Mode, "Mode should change")
 	assert.Equalf(t, entity.FAILED, state.Status, "Status should change")
 	assert.Equalf(t, "4", version, "Version should change and include 1 retry")
+	assert.Equalf(t, []string{entity.SwitchoverStartedReason, entity.SwitchoverRetriedReason, entity.SwitchoverStartedReason,
+		entity.SwitchoverFailedReason}, ctr.eventRecorder.(*TestEventRecorder).Reasons, "Events should be recorded")
 }
 
 func TestController_handleAddEmpty(t *testing.T) {
 	ctr := buildController(buildControllerFunc(entity.ACTIVE, entity.DONE, false))
 	newResource := &unstructured.Unstructured{}
 
-	ctr.handleEvent(nil, newResource, watch.Added)
+	handleEvent(ctr, context.Background(), nil, newResource, watch.Added)
 
 	state, _ := ctr.crKubernetesRepo.GetDrStatus(ctr.config.StatusPath)
 	version, _ := ctr.crKubernetesRepo.GetResourceVersion()
@@ -141,7 +211,7 @@ func TestController_handleSuccessfulModify(t *testing.T) {
 	oldResource := buildCustomResource(entity.STANDBY, entity.STANDBY, entity.QUEUE, "")
 	newResource := buildCustomResource(entity.ACTIVE, entity.STANDBY, entity.QUEUE, "")
 
-	ctr.handleEvent(oldResource, newResource, watch.Modified)
+	handleEvent(ctr, context.Background(), oldResource, newResource, watch.Modified)
 
 	state, _ := ctr.crKubernetesRepo.GetDrStatus(ctr.config.StatusPath)
 	version, _ := ctr.crKubernetesRepo.GetResourceVersion()
@@ -155,7 +225,7 @@ func TestController_handleFailedModify(t *testing.T) {
 	oldResource := buildCustomResource(entity.STANDBY, entity.STANDBY, entity.QUEUE, "")
 	newResource := buildCustomResource(entity.ACTIVE, entity.STANDBY, entity.QUEUE, "")
 
-	ctr.handleEvent(oldResource, newResource, watch.Modified)
+	handleEvent(ctr, context.Background(), oldResource, newResource, watch.Modified)
 
 	state, _ := ctr.crKubernetesRepo.GetDrStatus(ctr.config.StatusPath)
 	version, _ := ctr.crKubernetesRepo.GetResourceVersion()
@@ -169,7 +239,7 @@ func TestController_handleModifyOnlyQueue(t *testing.T) {
 	oldResource := buildCustomResource(entity.ACTIVE, entity.ACTIVE, entity.DONE, "")
 	newResource := buildCustomResource(entity.ACTIVE, entity.ACTIVE, entity.QUEUE, "")
 
-	ctr.handleEvent(oldResource, newResource, watch.Modified)
+	handleEvent(ctr, context.Background(), oldResource, newResource, watch.Modified)
 
 	state, _ := ctr.crKubernetesRepo.GetDrStatus(ctr.config.StatusPath)
 	version, _ := ctr.crKubernetesRepo.GetResourceVersion()
@@ -183,7 +253,7 @@ func TestController_handleModifyOnlyQueueForFailed(t *testing.T) {
 	oldResource := buildCustomResource(entity.ACTIVE, entity.ACTIVE, entity.FAILED, "")
 	newResource := buildCustomResource(entity.ACTIVE, entity.ACTIVE, entity.QUEUE, "")
 
-	ctr.handleEvent(oldResource, newResource, watch.Modified)
+	handleEvent(ctr, context.Background(), oldResource, newResource, watch.Modified)
 
 	state, _ := ctr.crKubernetesRepo.GetDrStatus(ctr.config.StatusPath)
 	version, _ := ctr.crKubernetesRepo.GetResourceVersion()
@@ -197,7 +267,7 @@ func TestController_handleModifyAnnotationForFailed(t *testing.T) {
 	oldResource := buildCustomResource(entity.ACTIVE, entity.ACTIVE, entity.QUEUE, "")
 	newResource := buildCustomResource(entity.ACTIVE, entity.ACTIVE, entity.QUEUE, "1")
 
-	ctr.handleEvent(oldResource, newResource, watch.Modified)
+	handleEvent(ctr, context.Background(), oldResource, newResource, watch.Modified)
 
 	state, _ := ctr.crKubernetesRepo.GetDrStatus(ctr.config.StatusPath)
 	version, _ := ctr.crKubernetesRepo.GetResourceVersion()
@@ -206,6 +276,761 @@ func TestController_handleModifyAnnotationForFailed(t *testing.T) {
 	assert.Equalf(t, "3", version, "Version should change")
 }
 
+func TestController_passPreviousState(t *testing.T) {
+	var requests []entity.ControllerRequest
+	controllerFunc := func(request entity.ControllerRequest) (entity.ControllerResponse, error) {
+		requests = append(requests, request)
+		return entity.ControllerResponse{SwitchoverState: entity.SwitchoverState{Mode: request.Mode, Status: entity.DONE}}, nil
+	}
+
+	handleEvent(buildController(controllerFunc), context.Background(), buildCustomResource(entity.STANDBY, entity.STANDBY, entity.DONE, ""),
+		buildCustomResource(entity.ACTIVE, entity.STANDBY, entity.DONE, ""), watch.Modified)
+	handleEvent(buildController(controllerFunc), context.Background(), buildCustomResource(entity.ACTIVE, entity.ACTIVE, entity.FAILED, ""),
+		buildCustomResource(entity.ACTIVE, entity.ACTIVE, entity.FAILED, "1"), watch.Modified)
+
+	assert.Lenf(t, requests, 2, "Function should be called for both events")
+	assert.Equalf(t, entity.STANDBY, requests[0].PreviousMode, "Previous mode should be passed")
+	assert.Falsef(t, requests[0].RetryRequested, "New mode should not be treated as retry")
+	assert.Equalf(t, []entity.FieldChange{{Path: "data.mode", OldValue: entity.STANDBY, NewValue: entity.ACTIVE}},
+		requests[0].Diff, "Diff should contain changed mode")
+	assert.Equalf(t, entity.ACTIVE, requests[1].PreviousMode, "Previous mode should be passed")
+	assert.Truef(t, requests[1].RetryRequested, "Annotation change should be treated as retry")
+	assert.Equalf(t, []entity.FieldChange{{Path: "metadata.annotations." + usecase.SwitchoverAnnotationKey, OldValue: "", NewValue: "1"}},
+		requests[1].Diff, "Diff should contain changed annotation")
+}
+
+func TestController_keepPreviousStateOfCollapsedEvents(t *testing.T) {
+	var requests []entity.ControllerRequest
+	ctr := buildController(func(request entity.ControllerRequest) (entity.ControllerResponse, error) {
+		requests = append(requests, request)
+		return entity.ControllerResponse{SwitchoverState: entity.SwitchoverState{Mode: request.Mode, Status: entity.DONE}}, nil
+	})
+	ctr.handleEvent(buildCustomResource(entity.STANDBY, "", "", ""), buildCustomResource(entity.DISABLED, "", "", ""), watch.Modified)
+	ctr.handleEvent(buildCustomResource(entity.DISABLED, "", "", ""), buildCustomResource(entity.ACTIVE, "", "", ""), watch.Modified)
+
+	processQueue(ctr, context.Background())
+
+	assert.Lenf(t, requests, 1, "Function should be called once")
+	assert.Equalf(t, entity.STANDBY, requests[0].PreviousMode, "Previous mode should be taken from the first collapsed event")
+}
+
+func TestController_cancelQueuedSwitchover(t *testing.T) {
+	calls := 0
+	ctr := buildController(func(request entity.ControllerRequest) (entity.ControllerResponse, error) {
+		calls++
+		return entity.ControllerResponse{}, nil
+	})
+	oldResource := buildCustomResource(entity.STANDBY, entity.STANDBY, entity.DONE, "")
+	newResource := buildCustomResource(entity.ACTIVE, entity.STANDBY, entity.QUEUE, "")
+	cancelledResource := newResource.DeepCopy()
+	_ = unstructured.SetNestedField(cancelledResource.Object, `{"id":"1","caller":"site-manager"}`,
+		"metadata", "annotations", usecase.CancelAnnotationKey)
+
+	ctr.handleEvent(oldResource, newResource, watch.Modified)
+	handleEvent(ctr, context.Background(), newResource, cancelledResource, watch.Modified)
+
+	state, _ := ctr.crKubernetesRepo.GetDrStatus(ctr.config.StatusPath)
+	assert.Equalf(t, 0, calls, "Function should not be called")
+	assert.Equalf(t, entity.FAILED, state.Status, "Switchover should be cancelled")
+	assert.Equalf(t, "cancelled by site-manager", state.Comment, "Comment should contain the caller")
+	assert.Equalf(t, []string{entity.SwitchoverCancelledReason}, ctr.eventRecorder.(*TestEventRecorder).Reasons,
+		"Cancellation should be recorded")
+}
+
+func TestController_cancelQueueStatusWithoutRequest(t *testing.T) {
+	calls := 0
+	ctr := buildController(func(request entity.ControllerRequest) (entity.ControllerResponse, error) {
+		calls++
+		return entity.ControllerResponse{}, nil
+	})
+	queuedResource := buildCustomResource(entity.ACTIVE, entity.STANDBY, entity.QUEUE, "")
+	cancelledResource := queuedResource.DeepCopy()
+	_ = unstructured.SetNestedField(cancelledResource.Object, `{"id":"1","caller":"site-manager"}`,
+		"metadata", "annotations", usecase.CancelAnnotationKey)
+
+	handleEvent(ctr, context.Background(), queuedResource, cancelledResource, watch.Modified)
+
+	state, _ := ctr.crKubernetesRepo.GetDrStatus(ctr.config.StatusPath)
+	assert.Equalf(t, 0, calls, "Function should not be called")
+	assert.Equalf(t, entity.FAILED, state.Status, "Switchover should be cancelled")
+	assert.Equalf(t, entity.ACTIVE, state.Mode, "Mode should be the requested one")
+	assert.Equalf(t, "cancelled by site-manager", state.Comment, "Comment should contain the caller")
+}
+
+func TestController_handleInterrupted(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ctr := buildController(emptyControllerFunc).
+		WithContextFunc(func(ctx context.Context, request entity.ControllerRequest) (entity.ControllerResponse, error) {
+			cancel()
+			<-ctx.Done()
+			return entity.ControllerResponse{}, ctx.Err()
+		})
+	newResource := buildCustomResource(entity.ACTIVE, "", "", "")
+
+	handleEvent(ctr, ctx, nil, newResource, watch.Added)
+
+	state, _ := ctr.crKubernetesRepo.GetDrStatus(ctr.config.StatusPath)
+	assert.Equalf(t, entity.ACTIVE, state.Mode, "Mode should change")
+	assert.Equalf(t, entity.FAILED, state.Status, "Status should change")
+	assert.Containsf(t, state.Comment, interruptedComment, "Comment should explain interruption")
+}
+
+func TestController_skipEventAfterShutdown(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	ctr := buildController(buildControllerFunc(entity.ACTIVE, entity.DONE, false))
+	newResource := buildCustomResource(entity.ACTIVE, "", "", "")
+
+	handleEvent(ctr, ctx, nil, newResource, watch.Added)
+
+	version, _ := ctr.crKubernetesRepo.GetResourceVersion()
+	assert.Equalf(t, "1", version, "Version shouldn't change")
+}
+
+func TestController_skipEventForFollower(t *testing.T) {
+	ctr := buildController(buildControllerFunc(entity.ACTIVE, entity.DONE, false)).
+		WithLeaderElection("test-lease", "test", 15*time.Second, 10*time.Second, 2*time.Second)
+	newResource := buildCustomResource(entity.ACTIVE, "", "", "")
+
+	handleEvent(ctr, context.Background(), nil, newResource, watch.Added)
+	version, _ := ctr.crKubernetesRepo.GetResourceVersion()
+	assert.Equalf(t, "1", version, "Follower shouldn't change version")
+
+	ctr.leader.Store(true)
+	handleEvent(ctr, context.Background(), nil, newResource, watch.Added)
+	state, _ := ctr.crKubernetesRepo.GetDrStatus(ctr.config.StatusPath)
+	assert.Equalf(t, entity.DONE, state.Status, "Leader should perform switchover")
+}
+
+func TestController_stopOnLeadershipLoss(t *testing.T) {
+	var calls atomic.Int32
+	ctr := buildController(emptyControllerFunc).
+		WithLeaderElection("test-lease", "test", 15*time.Second, 10*time.Second, 2*time.Second)
+	ctr.WithContextFunc(func(ctx context.Context, request entity.ControllerRequest) (entity.ControllerResponse, error) {
+		calls.Add(1)
+		ctr.stopLeading()
+		<-ctx.Done()
+		return entity.ControllerResponse{}, ctx.Err()
+	})
+	ctr.leader.Store(true)
+	newResource := buildCustomResource(entity.ACTIVE, "", "", "")
+
+	handleEvent(ctr, context.Background(), nil, newResource, watch.Added)
+
+	state, _ := ctr.crKubernetesRepo.GetDrStatus(ctr.config.StatusPath)
+	assert.Equalf(t, int32(1), calls.Load(), "Switchover shouldn't be retried by former leader")
+	assert.Equalf(t, entity.RUNNING, state.Status, "Former leader shouldn't change status")
+	assert.NotContainsf(t, ctr.eventRecorder.(*TestEventRecorder).Reasons, entity.SwitchoverFailedReason,
+		"Former leader shouldn't fail switchover")
+}
+
+func TestController_coalesceEvents(t *testing.T) {
+	calls := 0
+	ctr := buildController(func(request entity.ControllerRequest) (entity.ControllerResponse, error) {
+		calls++
+		return entity.ControllerResponse{SwitchoverState: entity.SwitchoverState{Mode: request.Mode, Status: entity.DONE}}, nil
+	})
+	ctr.handleEvent(nil, buildCustomResource(entity.STANDBY, "", "", ""), watch.Added)
+	ctr.handleEvent(buildCustomResource(entity.STANDBY, "", "", ""), buildCustomResource(entity.ACTIVE, "", "", ""), watch.Modified)
+	assert.Equalf(t, 1, ctr.queue.Len(), "Events should collapse into one item")
+
+	processQueue(ctr, context.Background())
+
+	state, _ := ctr.crKubernetesRepo.GetDrStatus(ctr.config.StatusPath)
+	assert.Equalf(t, 1, calls, "Function should be called once")
+	assert.Equalf(t, entity.ACTIVE, state.Mode, "The latest mode should be applied")
+	assert.Equalf(t, entity.DONE, state.Status, "Status should change")
+}
+
+func TestController_reportProgress(t *testing.T) {
+	var progressState entity.SwitchoverState
+	ctr := buildController(emptyControllerFunc)
+	ctr.WithFunc(func(request entity.ControllerRequest) (entity.ControllerResponse, error) {
+		request.Progress.Report("promote replica", 40, "waiting for replica")
+		progressState, _ = ctr.crKubernetesRepo.GetDrStatus(ctr.config.StatusPath)
+		return entity.ControllerResponse{SwitchoverState: entity.SwitchoverState{Mode: request.Mode, Status: entity.DONE}}, nil
+	})
+	newResource := buildCustomResource(entity.ACTIVE, "", "", "")
+
+	handleEvent(ctr, context.Background(), nil, newResource, watch.Added)
+
+	assert.Equalf(t, entity.RUNNING, progressState.Status, "Status should stay running")
+	assert.Equalf(t, "Switchover step 'promote replica' is in progress (40%): waiting for replica", progressState.Comment,
+		"Comment should contain progress")
+	state, _ := ctr.crKubernetesRepo.GetDrStatus(ctr.config.StatusPath)
+	assert.Equalf(t, entity.DONE, state.Status, "Status should change")
+}
+
+func TestController_ignoreProgressAfterTimeout(t *testing.T) {
+	release := make(chan struct{})
+	ctr := buildController(emptyControllerFunc).
+		WithTimeout(100 * time.Millisecond).
+		WithContextFunc(func(ctx context.Context, request entity.ControllerRequest) (entity.ControllerResponse, error) {
+			<-release
+			request.Progress.Report("promote replica", 40, "")
+			return entity.ControllerResponse{}, ctx.Err()
+		})
+	ctr.WithGracePeriod(100 * time.Millisecond)
+	newResource := buildCustomResource(entity.ACTIVE, "", "", "")
+
+	handleEvent(ctr, context.Background(), nil, newResource, watch.Added)
+	close(release)
+	assert.Eventuallyf(t, func() bool { return !ctr.funcRunning.Load() }, time.Second, 10*time.Millisecond,
+		"DR function should return")
+
+	state, _ := ctr.crKubernetesRepo.GetDrStatus(ctr.config.StatusPath)
+	assert.Equalf(t, entity.FAILED, state.Status, "Progress shouldn't overwrite final status")
+}
+
+func TestController_handleTimeout(t *testing.T) {
+	var calls atomic.Int32
+	ctr := buildController(emptyControllerFunc).
+		WithTimeout(100 * time.Millisecond).
+		WithContextFunc(func(ctx context.Context, request entity.ControllerRequest) (entity.ControllerResponse, error) {
+			calls.Add(1)
+			<-ctx.Done()
+			return entity.ControllerResponse{}, ctx.Err()
+		})
+	newResource := buildCustomResource(entity.ACTIVE, "", "", "")
+
+	handleEvent(ctr, context.Background(), nil, newResource, watch.Added)
+
+	state, _ := ctr.crKubernetesRepo.GetDrStatus(ctr.config.StatusPath)
+	assert.Equalf(t, int32(1), calls.Load(), "Timeout shouldn't be retried")
+	assert.Equalf(t, entity.FAILED, state.Status, "Status should change")
+	assert.Equalf(t, (&TimeoutError{Timeout: 100 * time.Millisecond}).Error(), state.Comment, "Comment should contain timeout")
+}
+
+func TestController_postponeWhileFunctionIsRunning(t *testing.T) {
+	var calls atomic.Int32
+	release := make(chan struct{})
+	var released atomic.Bool
+	ctr := buildController(emptyControllerFunc).
+		WithTimeout(100 * time.Millisecond).
+		WithContextFunc(func(ctx context.Context, request entity.ControllerRequest) (entity.ControllerResponse, error) {
+			if calls.Add(1) == 1 {
+				<-release
+			}
+			return entity.ControllerResponse{SwitchoverState: entity.SwitchoverState{Mode: request.Mode, Status: entity.DONE}}, nil
+		})
+	ctr.WithGracePeriod(100 * time.Millisecond)
+	ctr.delay = 50 * time.Millisecond
+	oldResource := buildCustomResource(entity.ACTIVE, "", "", "")
+	newResource := buildCustomResource(entity.STANDBY, entity.ACTIVE, entity.FAILED, "")
+
+	handleEvent(ctr, context.Background(), nil, oldResource, watch.Added)
+	state, _ := ctr.crKubernetesRepo.GetDrStatus(ctr.config.StatusPath)
+	assert.Equalf(t, entity.FAILED, state.Status, "Status should change after timeout")
+
+	_ = ctr.crKubernetesRepo.UpdateStatus(ctr.config.StatusPath, entity.SwitchoverState{Mode: entity.ACTIVE, Status: entity.QUEUE})
+	time.AfterFunc(300*time.Millisecond, func() {
+		released.Store(true)
+		close(release)
+	})
+	ctr.handleEvent(oldResource, newResource, watch.Modified)
+	processQueue(ctr, context.Background())
+
+	state, _ = ctr.crKubernetesRepo.GetDrStatus(ctr.config.StatusPath)
+	assert.Truef(t, released.Load(), "Next switchover should wait for previous DR function")
+	assert.Equalf(t, int32(2), calls.Load(), "DR function should be called for next switchover")
+	assert.Equalf(t, entity.DONE, state.Status, "Status should change")
+}
+
+func TestController_failAfterPostponements(t *testing.T) {
+	var calls atomic.Int32
+	release := make(chan struct{})
+	defer close(release)
+	ctr := buildController(emptyControllerFunc).
+		WithTimeout(100 * time.Millisecond).
+		WithGracePeriod(10 * time.Millisecond).
+		WithContextFunc(func(ctx context.Context, request entity.ControllerRequest) (entity.ControllerResponse, error) {
+			calls.Add(1)
+			<-release
+			return entity.ControllerResponse{}, ctx.Err()
+		})
+	ctr.delay = 10 * time.Millisecond
+	oldResource := buildCustomResource(entity.ACTIVE, "", "", "")
+	newResource := buildCustomResource(entity.STANDBY, entity.ACTIVE, entity.FAILED, "")
+
+	handleEvent(ctr, context.Background(), nil, oldResource, watch.Added)
+	_ = ctr.crKubernetesRepo.UpdateStatus(ctr.config.StatusPath, entity.SwitchoverState{Mode: entity.ACTIVE, Status: entity.QUEUE})
+	handleEvent(ctr, context.Background(), oldResource, newResource, watch.Modified)
+
+	state, _ := ctr.crKubernetesRepo.GetDrStatus(ctr.config.StatusPath)
+	assert.Equalf(t, int32(1), calls.Load(), "DR function shouldn't be called while previous one is running")
+	assert.Equalf(t, entity.FAILED, state.Status, "Switchover should fail after postponements")
+	assert.Equalf(t, entity.STANDBY, state.Mode, "Mode should be the requested one")
+	assert.Equalf(t, funcRunningComment, state.Comment, "Comment should explain failure")
+	reasons := ctr.eventRecorder.(*TestEventRecorder).Reasons
+	assert.Equalf(t, entity.SwitchoverFailedReason, reasons[len(reasons)-1], "Failure should be recorded")
+}
+
+func TestController_failStaleSwitchover(t *testing.T) {
+	calls := 0
+	ctr := buildController(func(request entity.ControllerRequest) (entity.ControllerResponse, error) {
+		calls++
+		return entity.ControllerResponse{SwitchoverState: entity.SwitchoverState{Mode: request.Mode, Status: entity.DONE}}, nil
+	}).WithStaleSwitchoverPolicy(repoConfig.FailStaleSwitchover)
+	ctr.recoveryPending.Store(true)
+	newResource := buildCustomResource(entity.ACTIVE, entity.STANDBY, entity.RUNNING, "")
+
+	handleEvent(ctr, context.Background(), nil, newResource, watch.Added)
+
+	state, _ := ctr.crKubernetesRepo.GetDrStatus(ctr.config.StatusPath)
+	assert.Equalf(t, 0, calls, "Function shouldn't be called")
+	assert.Equalf(t, entity.FAILED, state.Status, "Status should change")
+	assert.Equalf(t, "Switchover in 'running' status was interrupted by DR controller restart", state.Comment,
+		"Comment should explain failure")
+}
+
+func TestController_processRecentlyQueuedSwitchover(t *testing.T) {
+	ctr := buildController(buildControllerFunc(entity.ACTIVE, entity.DONE, false)).
+		WithStaleSwitchoverPolicy(repoConfig.FailStaleSwitchover)
+	ctr.config.DisasterRecoveryStatusPath.StartTimePath = []string{"data", "status_startTime"}
+	ctr.startRecovery()
+	newResource := buildCustomResource(entity.ACTIVE, entity.STANDBY, entity.QUEUE, "")
+	_ = unstructured.SetNestedField(newResource.Object, time.Now().UTC().Format(time.RFC3339), "data", "status_startTime")
+
+	handleEvent(ctr, context.Background(), nil, newResource, watch.Added)
+
+	state, _ := ctr.crKubernetesRepo.GetDrStatus(ctr.config.StatusPath)
+	assert.Equalf(t, entity.DONE, state.Status, "Recently queued switchover should be performed")
+}
+
+func TestController_failStaleQueuedSwitchover(t *testing.T) {
+	calls := 0
+	ctr := buildController(func(request entity.ControllerRequest) (entity.ControllerResponse, error) {
+		calls++
+		return entity.ControllerResponse{SwitchoverState: entity.SwitchoverState{Mode: request.Mode, Status: entity.DONE}}, nil
+	}).WithStaleSwitchoverPolicy(repoConfig.FailStaleSwitchover)
+	ctr.config.DisasterRecoveryStatusPath.StartTimePath = []string{"data", "status_startTime"}
+	ctr.startRecovery()
+	newResource := buildCustomResource(entity.ACTIVE, entity.STANDBY, entity.QUEUE, "")
+	_ = unstructured.SetNestedField(newResource.Object, "2025-01-01T10:00:00Z", "data", "status_startTime")
+
+	handleEvent(ctr, context.Background(), nil, newResource, watch.Added)
+
+	state, _ := ctr.crKubernetesRepo.GetDrStatus(ctr.config.StatusPath)
+	assert.Equalf(t, 0, calls, "Function shouldn't be called")
+	assert.Equalf(t, entity.FAILED, state.Status, "Status should change")
+	assert.Equalf(t, "Switchover in 'queue' status at 2025-01-01T10:00:00Z was interrupted by DR controller restart", state.Comment,
+		"Comment should explain failure")
+}
+
+func TestController_resumeStaleSwitchover(t *testing.T) {
+	ctr := buildController(buildControllerFunc(entity.ACTIVE, entity.DONE, false)).
+		WithStaleSwitchoverPolicy(repoConfig.ResumeStaleSwitchover)
+	ctr.recoveryPending.Store(true)
+	newResource := buildCustomResource(entity.ACTIVE, entity.STANDBY, entity.RUNNING, "")
+
+	handleEvent(ctr, context.Background(), nil, newResource, watch.Added)
+
+	state, _ := ctr.crKubernetesRepo.GetDrStatus(ctr.config.StatusPath)
+	assert.Equalf(t, entity.DONE, state.Status, "Switchover should be resumed")
+	assert.Falsef(t, ctr.recoveryPending.Load(), "Recovery should be performed once")
+}
+
+func TestController_requeueInProgress(t *testing.T) {
+	var attempts []int
+	ctr := buildController(func(request entity.ControllerRequest) (entity.ControllerResponse, error) {
+		attempts = append(attempts, request.Attempt)
+		if request.Attempt < 3 {
+			return entity.ControllerResponse{
+				SwitchoverState: entity.SwitchoverState{Comment: "replica promotion is in progress"},
+				RequeueAfter:    10 * time.Millisecond,
+			}, nil
+		}
+		return entity.ControllerResponse{SwitchoverState: entity.SwitchoverState{Mode: request.Mode, Status: entity.DONE}}, nil
+	})
+	newResource := buildCustomResource(entity.ACTIVE, "", "", "")
+
+	handleEvent(ctr, context.Background(), nil, newResource, watch.Added)
+
+	state, _ := ctr.crKubernetesRepo.GetDrStatus(ctr.config.StatusPath)
+	version, _ := ctr.crKubernetesRepo.GetResourceVersion()
+	assert.Equalf(t, []int{1, 2, 3}, attempts, "Function should be called until switchover is finished")
+	assert.Equalf(t, entity.DONE, state.Status, "Status should change")
+	assert.Equalf(t, "5", version, "Version should change for running, two requeues and result")
+}
+
+func TestController_handlePermanentError(t *testing.T) {
+	calls := 0
+	ctr := buildController(func(request entity.ControllerRequest) (entity.ControllerResponse, error) {
+		calls++
+		return entity.ControllerResponse{}, Permanent(fmt.Errorf("invalid replica configuration"))
+	})
+	newResource := buildCustomResource(entity.ACTIVE, "", "", "")
+
+	handleEvent(ctr, context.Background(), nil, newResource, watch.Added)
+
+	state, _ := ctr.crKubernetesRepo.GetDrStatus(ctr.config.StatusPath)
+	assert.Equalf(t, 1, calls, "Permanent error shouldn't be retried")
+	assert.Equalf(t, entity.FAILED, state.Status, "Status should change")
+	assert.Equalf(t, "invalid replica configuration", state.Comment, "Comment should contain original error")
+}
+
+func TestController_rollbackFailedSwitchover(t *testing.T) {
+	var rollbackModes []string
+	ctr := buildController(buildControllerFunc(entity.ACTIVE, entity.DONE, true)).
+		WithRollbackFunc(func(request entity.ControllerRequest, err error) (entity.ControllerResponse, error) {
+			rollbackModes = append(rollbackModes, request.Mode)
+			return entity.ControllerResponse{SwitchoverState: entity.SwitchoverState{Mode: request.Mode, Status: entity.DONE}}, nil
+		})
+	newResource := buildCustomResource(entity.ACTIVE, entity.STANDBY, entity.DONE, "")
+
+	handleEvent(ctr, context.Background(), nil, newResource, watch.Added)
+
+	state, _ := ctr.crKubernetesRepo.GetDrStatus(ctr.config.StatusPath)
+	assert.Equalf(t, []string{entity.STANDBY}, rollbackModes, "Rollback should be called once with previous mode")
+	assert.Equalf(t, entity.FAILED, state.Status, "Status should change")
+	assert.Equalf(t, "test error; rolled back to 'standby' mode", state.Comment, "Comment should contain rollback outcome")
+	assert.Containsf(t, ctr.eventRecorder.(*TestEventRecorder).Reasons, entity.SwitchoverRolledBackReason,
+		"Rollback event should be recorded")
+}
+
+func TestController_rollbackFailedResponse(t *testing.T) {
+	var rollbackModes []string
+	ctr := buildController(func(request entity.ControllerRequest) (entity.ControllerResponse, error) {
+		return entity.ControllerResponse{SwitchoverState: entity.SwitchoverState{Mode: request.Mode, Status: entity.FAILED,
+			Comment: "replica is not ready"}}, nil
+	}).WithRollbackFunc(func(request entity.ControllerRequest, err error) (entity.ControllerResponse, error) {
+		rollbackModes = append(rollbackModes, request.Mode)
+		return entity.ControllerResponse{SwitchoverState: entity.SwitchoverState{Mode: request.Mode, Status: entity.DONE}}, nil
+	})
+	newResource := buildCustomResource(entity.ACTIVE, entity.STANDBY, entity.DONE, "")
+
+	handleEvent(ctr, context.Background(), nil, newResource, watch.Added)
+
+	state, _ := ctr.crKubernetesRepo.GetDrStatus(ctr.config.StatusPath)
+	assert.Equalf(t, []string{entity.STANDBY}, rollbackModes, "Rollback should be called once with previous mode")
+	assert.Equalf(t, entity.FAILED, state.Status, "Status should stay failed")
+	assert.Equalf(t, "replica is not ready; rolled back to 'standby' mode", state.Comment,
+		"Comment should contain rollback outcome")
+}
+
+func TestController_handleDeleteAndRecreate(t *testing.T) {
+	calls := 0
+	var deletedModes []string
+	ctr := buildController(func(request entity.ControllerRequest) (entity.ControllerResponse, error) {
+		calls++
+		return entity.ControllerResponse{SwitchoverState: entity.SwitchoverState{Mode: request.Mode, Status: entity.DONE}}, nil
+	}).WithDeleteFunc(func(request entity.ControllerRequest) {
+		deletedModes = append(deletedModes, request.Mode)
+	})
+	resource := buildCustomResource(entity.ACTIVE, "", "", "")
+
+	handleEvent(ctr, context.Background(), nil, resource, watch.Added)
+	ctr.handleDelete(cache.DeletedFinalStateUnknown{Key: "my-namespace/example-service-dr-config", Obj: resource})
+	processQueue(ctr, context.Background())
+	handleEvent(ctr, context.Background(), nil, resource, watch.Added)
+
+	assert.Equalf(t, []string{entity.ACTIVE}, deletedModes, "Delete function should be called with the last known state")
+	assert.Equalf(t, 2, calls, "Recreated resource should be processed")
+}
+
+func TestController_handleRetryAfterError(t *testing.T) {
+	var attempts []int
+	ctr := buildController(func(request entity.ControllerRequest) (entity.ControllerResponse, error) {
+		attempts = append(attempts, request.Attempt)
+		if request.Attempt == 1 {
+			return entity.ControllerResponse{}, RetryAfter(fmt.Errorf("replica is not ready"), 10*time.Millisecond)
+		}
+		return entity.ControllerResponse{SwitchoverState: entity.SwitchoverState{Mode: request.Mode, Status: entity.DONE}}, nil
+	}).WithRetry(3, time.Hour)
+	newResource := buildCustomResource(entity.ACTIVE, "", "", "")
+
+	handleEvent(ctr, context.Background(), nil, newResource, watch.Added)
+
+	state, _ := ctr.crKubernetesRepo.GetDrStatus(ctr.config.StatusPath)
+	assert.Equalf(t, []int{1, 2}, attempts, "Function should be retried after requested delay")
+	assert.Equalf(t, entity.DONE, state.Status, "Status should change")
+}
+
+func TestController_runWithFakeClients(t *testing.T) {
+	ctr := buildController(buildControllerFunc(entity.ACTIVE, entity.DONE, false))
+	ctr.config.Namespace = "my-namespace"
+	ctr.config.CustomResourceConfig.Name = "example-service-dr-config"
+	resource := buildCustomResource(entity.ACTIVE, "", "", "")
+	resource.SetResourceVersion("1")
+	gvr := schema.GroupVersionResource{Version: "v1", Resource: "configmaps"}
+	dynClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{gvr: "ConfigMapList"}, resource)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- ctr.WithDynamicClient(dynClient).WithKubeClient(kubefake.NewSimpleClientset()).Run(ctx)
+	}()
+
+	crRepo := repo.NewKubernetesCustomResourceRepo(dynClient, gvr, ctr.config.Name, ctr.config.Namespace)
+	assert.Eventuallyf(t, func() bool {
+		state, err := crRepo.GetDrStatus(ctr.config.StatusPath)
+		return err == nil && state.Status == entity.DONE
+	}, 5*time.Second, 10*time.Millisecond, "Switchover should finish")
+	cancel()
+	assert.NoErrorf(t, <-done, "Controller should stop without error")
+}
+
+func TestTypedController_decodeResource(t *testing.T) {
+	var requests []TypedRequest[corev1.ConfigMap]
+	ctr := (&TypedController[corev1.ConfigMap]{Controller: buildController(emptyControllerFunc)}).
+		WithFunc(func(request TypedRequest[corev1.ConfigMap]) (entity.ControllerResponse, error) {
+			requests = append(requests, request)
+			return entity.ControllerResponse{SwitchoverState: entity.SwitchoverState{Mode: request.Mode, Status: entity.DONE}}, nil
+		})
+	oldResource := buildCustomResource(entity.STANDBY, entity.STANDBY, entity.DONE, "")
+	newResource := buildCustomResource(entity.ACTIVE, entity.STANDBY, entity.DONE, "")
+
+	handleEvent(ctr, context.Background(), oldResource, newResource, watch.Modified)
+
+	assert.Lenf(t, requests, 1, "Function should be called once")
+	assert.Equalf(t, entity.ACTIVE, requests[0].Resource.Data["mode"], "New resource should be decoded")
+	assert.Equalf(t, entity.STANDBY, requests[0].OldResource.Data["mode"], "Old resource should be decoded")
+}
+
+func TestTypedController_failOnDecodeError(t *testing.T) {
+	type invalidResource struct {
+		Data struct {
+			Mode int `json:"mode"`
+		} `json:"data"`
+	}
+	calls := 0
+	rollbacks := 0
+	ctr := (&TypedController[invalidResource]{Controller: buildController(emptyControllerFunc)}).
+		WithFunc(func(request TypedRequest[invalidResource]) (entity.ControllerResponse, error) {
+			calls++
+			return entity.ControllerResponse{}, nil
+		}).
+		WithRollbackFunc(func(request entity.ControllerRequest, err error) (entity.ControllerResponse, error) {
+			rollbacks++
+			return entity.ControllerResponse{}, nil
+		})
+	oldResource := buildCustomResource(entity.STANDBY, entity.STANDBY, entity.DONE, "")
+	newResource := buildCustomResource(entity.ACTIVE, entity.STANDBY, entity.DONE, "")
+
+	handleEvent(ctr, context.Background(), oldResource, newResource, watch.Modified)
+
+	state, _ := ctr.crKubernetesRepo.GetDrStatus(ctr.config.StatusPath)
+	assert.Equalf(t, 0, calls, "Function should not be called")
+	assert.Equalf(t, 0, rollbacks, "Rollback should not be called when function is not called")
+	assert.Equalf(t, entity.ACTIVE, state.Mode, "Mode should change")
+	assert.Equalf(t, entity.FAILED, state.Status, "Status should change")
+	assert.Containsf(t, state.Comment, "cannot decode DR resource", "Comment should contain decode error")
+}
+
+func TestParsePlans(t *testing.T) {
+	plans, err := ParsePlans(map[string]string{
+		entity.ACTIVE: `
+steps:
+- name: scale-up
+  type: scale
+  timeout: 1m
+  retries: 2
+  scale: {kind: Deployment, name: app, replicas: 2}
+`,
+	})
+	assert.NoErrorf(t, err, "Plan should be parsed")
+	assert.Equalf(t, time.Minute, plans[entity.ACTIVE].Steps[0].Timeout.Duration, "Step timeout should be parsed")
+	assert.Equalf(t, int32(2), plans[entity.ACTIVE].Steps[0].Scale.Replicas, "Scale action should be parsed")
+
+	_, err = ParsePlans(map[string]string{entity.ACTIVE: "steps:\n- name: unknown\n  type: reboot\n"})
+	assert.Errorf(t, err, "Unknown step type should be rejected")
+	_, err = ParsePlans(map[string]string{entity.ACTIVE: "steps:\n- name: scale-up\n  type: scale\n"})
+	assert.Errorf(t, err, "Step without action should be rejected")
+}
+
+func TestController_executePlan(t *testing.T) {
+	var httpCalls []string
+	httpServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		httpCalls = append(httpCalls, r.Method+" "+r.URL.Path)
+	}))
+	defer httpServer.Close()
+	replicas := int32(0)
+	kubeClient := kubefake.NewSimpleClientset(&appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "test"},
+		Spec:       appsv1.DeploymentSpec{Replicas: &replicas},
+		Status:     appsv1.DeploymentStatus{ReadyReplicas: 2, UpdatedReplicas: 2},
+	})
+	kubeClient.PrependReactor("create", "jobs", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		job := action.(k8stesting.CreateAction).GetObject().(*batchv1.Job)
+		job.Name = job.GenerateName + "1"
+		job.Status.Conditions = []batchv1.JobCondition{{Type: batchv1.JobComplete, Status: corev1.ConditionTrue}}
+		return false, nil, nil
+	})
+	gvr := schema.GroupVersionResource{Version: "v1", Resource: "configmaps"}
+	settings := &unstructured.Unstructured{}
+	settings.SetAPIVersion("v1")
+	settings.SetKind("ConfigMap")
+	settings.SetName("settings")
+	settings.SetNamespace("test")
+	dynClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{gvr: "ConfigMapList"}, settings)
+	ctr := buildController(emptyControllerFunc).WithPlans(map[string]Plan{
+		entity.ACTIVE: {Steps: []PlanStep{
+			{Name: "scale-up", Type: ScaleStep, Scale: &ScaleAction{Kind: "Deployment", Name: "app", Replicas: 2}},
+			{Name: "wait-for-app", Type: WaitReadyStep, WaitReady: &WaitAction{Kind: "Deployment", Name: "app"}},
+			{Name: "enable-writes", Type: PatchStep, Patch: &PatchAction{Version: "v1", Resource: "configmaps",
+				Name: "settings", Patch: `{"data":{"readOnly":"false"}}`}},
+			{Name: "notify", Type: HTTPStep, HTTP: &HTTPAction{Method: http.MethodPost, URL: httpServer.URL + "/active"}},
+			{Name: "migrate", Type: JobStep, Job: &JobAction{Name: "migrate"}},
+		}},
+	}).WithKubeClient(kubeClient).WithDynamicClient(dynClient)
+	newResource := buildCustomResource(entity.ACTIVE, "", "", "")
+
+	handleEvent(ctr, context.Background(), nil, newResource, watch.Added)
+
+	state, _ := ctr.crKubernetesRepo.GetDrStatus(ctr.config.StatusPath)
+	assert.Equalf(t, entity.DONE, state.Status, "Switchover should finish: %s", state.Comment)
+	deployment, _ := kubeClient.AppsV1().Deployments("test").Get(context.Background(), "app", metav1.GetOptions{})
+	assert.Equalf(t, int32(2), *deployment.Spec.Replicas, "Deployment should be scaled")
+	patched, _ := dynClient.Resource(gvr).Namespace("test").Get(context.Background(), "settings", metav1.GetOptions{})
+	readOnly, _, _ := unstructured.NestedString(patched.Object, "data", "readOnly")
+	assert.Equalf(t, "false", readOnly, "Config map should be patched")
+	assert.Equalf(t, []string{"POST /active"}, httpCalls, "Endpoint should be called")
+	jobs, _ := kubeClient.BatchV1().Jobs("test").List(context.Background(), metav1.ListOptions{})
+	assert.Lenf(t, jobs.Items, 1, "Job should be created")
+	assert.Equalf(t, jobTTLSecondsAfterFinished, *jobs.Items[0].Spec.TTLSecondsAfterFinished, "Finished Job should be deleted by TTL")
+}
+
+func TestIsStatefulSetReady(t *testing.T) {
+	replicas := int32(3)
+	partition := int32(1)
+	tests := []struct {
+		name      string
+		partition *int32
+		status    appsv1.StatefulSetStatus
+		expected  bool
+	}{
+		{name: "rolled out", expected: true, status: appsv1.StatefulSetStatus{ObservedGeneration: 2, ReadyReplicas: 3,
+			UpdatedReplicas: 3, CurrentRevision: "app-2", UpdateRevision: "app-2"}},
+		{name: "generation not observed", status: appsv1.StatefulSetStatus{ObservedGeneration: 1, ReadyReplicas: 3,
+			UpdatedReplicas: 3, CurrentRevision: "app-1", UpdateRevision: "app-1"}},
+		{name: "old pods are ready", status: appsv1.StatefulSetStatus{ObservedGeneration: 2, ReadyReplicas: 3,
+			UpdatedReplicas: 1, CurrentRevision: "app-1", UpdateRevision: "app-2"}},
+		{name: "partition rolled out", partition: &partition, expected: true, status: appsv1.StatefulSetStatus{
+			ObservedGeneration: 2, ReadyReplicas: 3, UpdatedReplicas: 2, CurrentRevision: "app-1", UpdateRevision: "app-2"}},
+	}
+	for _, test := range tests {
+		statefulSet := &appsv1.StatefulSet{
+			ObjectMeta: metav1.ObjectMeta{Name: "app", Generation: 2},
+			Spec:       appsv1.StatefulSetSpec{Replicas: &replicas},
+			Status:     test.status,
+		}
+		if test.partition != nil {
+			statefulSet.Spec.UpdateStrategy.RollingUpdate = &appsv1.RollingUpdateStatefulSetStrategy{Partition: test.partition}
+		}
+		assert.Equalf(t, test.expected, isStatefulSetReady(statefulSet), "Unexpected readiness when %s", test.name)
+	}
+}
+
+func TestController_failPlanStep(t *testing.T) {
+	calls := 0
+	httpServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer httpServer.Close()
+	ctr := buildController(emptyControllerFunc).WithPlans(map[string]Plan{
+		entity.ACTIVE: {Steps: []PlanStep{
+			{Name: "notify", Type: HTTPStep, Retries: 2, HTTP: &HTTPAction{URL: httpServer.URL}},
+		}},
+	}).WithKubeClient(kubefake.NewSimpleClientset())
+	newResource := buildCustomResource(entity.ACTIVE, "", "", "")
+
+	handleEvent(ctr, context.Background(), nil, newResource, watch.Added)
+
+	state, _ := ctr.crKubernetesRepo.GetDrStatus(ctr.config.StatusPath)
+	assert.Equalf(t, entity.FAILED, state.Status, "Switchover should fail")
+	assert.Containsf(t, state.Comment, "notify", "Comment should contain the failed step")
+	assert.Equalf(t, 3, calls, "Step should be retried")
+
+	_, err := ctr.executePlan(context.Background(), map[string]Plan{}, entity.ControllerRequest{RequestData: entity.RequestData{Mode: entity.STANDBY}})
+	var permanentErr *PermanentError
+	assert.Truef(t, errors.As(err, &permanentErr), "Switchover without plan should fail permanently")
+}
+
+func TestCommandFunc(t *testing.T) {
+	commandFunc := CommandFunc("sh", "-c", `grep -q '"mode":"active"' && echo '{"mode":"active","status":"done","comment":"promoted"}'`)
+	response, err := commandFunc(context.Background(), entity.ControllerRequest{RequestData: entity.RequestData{Mode: entity.ACTIVE}})
+	assert.NoErrorf(t, err, "Command should succeed")
+	assert.Equalf(t, entity.DONE, response.Status, "Status should be read from stdout")
+	assert.Equalf(t, "promoted", response.Comment, "Comment should be read from stdout")
+
+	_, err = CommandFunc("sh", "-c", "echo 'cannot promote' >&2; exit 1")(context.Background(), entity.ControllerRequest{})
+	assert.ErrorContainsf(t, err, "cannot promote", "Error should contain stderr")
+	var permanentErr *PermanentError
+	assert.Falsef(t, errors.As(err, &permanentErr), "Failed command should be retried")
+
+	_, err = CommandFunc("sh", "-c", "echo done")(context.Background(), entity.ControllerRequest{})
+	assert.Truef(t, errors.As(err, &permanentErr), "Invalid response should not be retried")
+
+	response, err = CommandFunc("sh", "-c", `echo '{"mode":"active","inProgress":true,"requeueAfter":30}'`)(
+		context.Background(), entity.ControllerRequest{})
+	assert.NoErrorf(t, err, "Command should succeed")
+	assert.Equalf(t, 30*time.Second, response.RequeueAfter, "Requeue delay should be read in seconds")
+
+	response, _ = CommandFunc("sh", "-c", `echo '{"mode":"active","requeueAfter":0.001}'`)(
+		context.Background(), entity.ControllerRequest{})
+	assert.Equalf(t, time.Second, response.RequeueAfter, "Requeue delay should not be less than a second")
+}
+
+func TestCommandFunc_killChildProcessesOnCancel(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	started := time.Now()
+
+	// the child process keeps stdout open, so the command returns only when the whole process group is killed
+	_, err := CommandFunc("sh", "-c", "sleep 30 & wait")(ctx, entity.ControllerRequest{})
+
+	assert.Errorf(t, err, "Cancelled command should fail")
+	assert.Lessf(t, time.Since(started), commandWaitDelay, "Child processes should be killed with the command")
+}
+
+func TestWebhookFunc(t *testing.T) {
+	var requests []entity.ControllerRequest
+	statusCode := http.StatusOK
+	httpServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request entity.ControllerRequest
+		_ = json.NewDecoder(r.Body).Decode(&request)
+		requests = append(requests, request)
+		assert.Equalf(t, "Bearer token", r.Header.Get("Authorization"), "Headers should be sent")
+		w.Header().Set("Retry-After", "7")
+		w.WriteHeader(statusCode)
+		_, _ = w.Write([]byte(`{"mode":"standby","status":"done"}`))
+	}))
+	defer httpServer.Close()
+	webhookFunc := WebhookFunc(httpServer.URL, map[string]string{"Authorization": "Bearer token"})
+	request := entity.ControllerRequest{RequestData: entity.RequestData{Mode: entity.STANDBY}, Attempt: 1}
+
+	response, err := webhookFunc(context.Background(), request)
+	assert.NoErrorf(t, err, "Webhook should succeed")
+	assert.Equalf(t, entity.DONE, response.Status, "Status should be read from response")
+	assert.Equalf(t, entity.STANDBY, requests[0].Mode, "Request should be sent")
+
+	statusCode = http.StatusServiceUnavailable
+	_, err = webhookFunc(context.Background(), request)
+	var retryAfterErr *RetryAfterError
+	assert.Truef(t, errors.As(err, &retryAfterErr) && retryAfterErr.Delay == 7*time.Second, "Retry-After should be respected")
+
+	statusCode = http.StatusBadRequest
+	_, err = webhookFunc(context.Background(), request)
+	var permanentErr *PermanentError
+	assert.Truef(t, errors.As(err, &permanentErr), "Client error should not be retried")
+}
+
+func TestJitterBackoffRateLimiter(t *testing.T) {
+	rateLimiter := newJitterBackoffRateLimiter(time.Second, 3*time.Second)
+	first := rateLimiter.When("item")
+	second := rateLimiter.When("item")
+	third := rateLimiter.When("item")
+	assert.Truef(t, first >= time.Second && first <= 1200*time.Millisecond, "First delay should be base delay with jitter, got %v", first)
+	assert.Truef(t, second >= 2*time.Second && second <= 2400*time.Millisecond, "Second delay should be doubled, got %v", second)
+	assert.Equalf(t, 3*time.Second, third, "Delay should be limited")
+	assert.Equalf(t, 3, rateLimiter.NumRequeues("item"), "Failures should be counted")
+	rateLimiter.Forget("item")
+	assert.Equalf(t, 0, rateLimiter.NumRequeues("item"), "Failures should be forgotten")
+}
+
 func buildController(controllerFunc func(request entity.ControllerRequest) (entity.ControllerResponse, error)) *Controller {
 	envs := make(map[string]string)
 	envs["DISASTER_RECOVERY_MODE_PATH"] = "data.mode"
@@ -227,11 +1052,27 @@ func buildController(controllerFunc func(request entity.ControllerRequest) (enti
 	ctr := &Controller{
 		delay:            1 * time.Second,
 		attempts:         2,
+		maxDelay:         maxRetryDelay,
+		gracePeriod:      defaultGracePeriod,
 		config:           config,
 		crKubernetesRepo: usecase.KubernetesCustomResourceRepo(&crRepo),
-		controllerFunc:   controllerFunc,
+		eventRecorder:    &TestEventRecorder{},
+		requests:         map[string]queuedRequest{},
+	}
+	ctr.queue = ctr.newQueue()
+	return ctr.WithFunc(controllerFunc)
+}
+
+// handleEvent passes event to controller and processes the queue until all requests are reconciled
+func handleEvent(ctr *Controller, ctx context.Context, old interface{}, new interface{}, eventType watch.EventType) {
+	ctr.handleEvent(old, new, eventType)
+	processQueue(ctr, ctx)
+}
+
+func processQueue(ctr *Controller, ctx context.Context) {
+	for len(ctr.requests) > 0 {
+		ctr.processNextItem(ctx)
 	}
-	return ctr
 }
 
 func buildControllerFunc(resultMode string, resultStatus string, isError bool) func(request entity.ControllerRequest) (entity.ControllerResponse, error) {
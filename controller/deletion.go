@@ -0,0 +1,69 @@
+// Copyright 2024-2025 NetCracker Technology Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"log"
+
+	"github.com/Netcracker/qubership-disaster-recovery-daemon/api/entity"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/tools/cache"
+)
+
+// WithDeleteFunc sets function which is called when DR resource is deleted. The request contains the last known
+// state of DR resource. Switchover which was waiting in the queue is dropped.
+func (ctr *Controller) WithDeleteFunc(deleteFunc func(request entity.ControllerRequest)) *Controller {
+	ctr.deleteFunc = deleteFunc
+	return ctr
+}
+
+// handleDelete puts deletion of DR resource to the queue, so it replaces pending request and is processed by the worker.
+func (ctr *Controller) handleDelete(obj interface{}) {
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	resource, ok := obj.(*unstructured.Unstructured)
+	if !ok || resource == nil {
+		log.Printf("Deleted DR resource has unexpected type %T", obj)
+		return
+	}
+	if !ctr.isLeader() {
+		log.Printf("Skip event. DR controller is not a leader")
+		return
+	}
+	controllerRequest, err := buildControllerRequest(resource.UnstructuredContent(), ctr.config)
+	if err != nil {
+		log.Printf("Cannot deserialize DR resource and build DR request: %v", err)
+		return
+	}
+	key, err := cache.DeletionHandlingMetaNamespaceKeyFunc(resource)
+	if err != nil {
+		log.Printf("Cannot build key for DR resource: %v", err)
+		return
+	}
+	ctr.enqueue(key, controllerRequest, watch.Deleted)
+}
+
+// handleDeletion forgets the state of deleted DR resource, so the first event of recreated resource is processed.
+func (ctr *Controller) handleDeletion(controllerRequest entity.ControllerRequest) {
+	log.Printf("DR resource was deleted, last known mode is '%s', status is '%+v'", controllerRequest.Mode, controllerRequest.Status)
+	ctr.resourceVersion = ""
+	// recreated resource may contain status which was left by the interrupted switchover
+	ctr.startRecovery()
+	if ctr.deleteFunc != nil {
+		ctr.deleteFunc(controllerRequest)
+	}
+}
@@ -0,0 +1,97 @@
+// Copyright 2024-2025 NetCracker Technology Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"log"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/Netcracker/qubership-disaster-recovery-daemon/api/entity"
+	"k8s.io/apimachinery/pkg/watch"
+)
+
+// diffIgnoredPaths are changed by API server on every update, so they are not reported in the diff
+var diffIgnoredPaths = map[string]bool{
+	"metadata.resourceVersion": true,
+	"metadata.managedFields":   true,
+	"metadata.generation":      true,
+}
+
+// setPreviousState fills the request with the previous state of DR resource and the changes made since that state.
+func setPreviousState(controllerRequest *entity.ControllerRequest, previous entity.ControllerRequest) {
+	controllerRequest.OldObject = previous.Object
+	controllerRequest.PreviousMode = previous.Mode
+	controllerRequest.RetryRequested = controllerRequest.Mode == previous.Mode &&
+		controllerRequest.SwitchoverAnnotation != previous.SwitchoverAnnotation
+	controllerRequest.Diff = diffObjects(previous.Object, controllerRequest.Object)
+}
+
+// keepPreviousState compares the request with the previous state of pending request which has not been processed yet,
+// so the changes of collapsed events are not lost.
+func (ctr *Controller) keepPreviousState(controllerRequest *entity.ControllerRequest, pending queuedRequest, eventType watch.EventType) {
+	if eventType != watch.Modified || pending.eventType == watch.Deleted || pending.attempt > 0 ||
+		pending.controllerRequest.OldObject == nil {
+		return
+	}
+	previous, err := buildControllerRequest(pending.controllerRequest.OldObject, ctr.config)
+	if err != nil {
+		log.Printf("Cannot deserialize previous state of DR resource: %v", err)
+		return
+	}
+	setPreviousState(controllerRequest, previous)
+}
+
+// diffObjects returns changed leaf fields of two objects sorted by path. Lists are compared as a whole.
+func diffObjects(oldObject, newObject map[string]interface{}) []entity.FieldChange {
+	var changes []entity.FieldChange
+	diffMaps("", oldObject, newObject, &changes)
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Path < changes[j].Path
+	})
+	return changes
+}
+
+func diffMaps(prefix string, oldMap, newMap map[string]interface{}, changes *[]entity.FieldChange) {
+	for key, oldValue := range oldMap {
+		diffValues(joinPath(prefix, key), oldValue, newMap[key], changes)
+	}
+	for key, newValue := range newMap {
+		if _, ok := oldMap[key]; !ok {
+			diffValues(joinPath(prefix, key), nil, newValue, changes)
+		}
+	}
+}
+
+func diffValues(path string, oldValue, newValue interface{}, changes *[]entity.FieldChange) {
+	if diffIgnoredPaths[path] || reflect.DeepEqual(oldValue, newValue) {
+		return
+	}
+	oldMap, oldIsMap := oldValue.(map[string]interface{})
+	newMap, newIsMap := newValue.(map[string]interface{})
+	if oldIsMap && newIsMap {
+		diffMaps(path, oldMap, newMap, changes)
+		return
+	}
+	*changes = append(*changes, entity.FieldChange{Path: path, OldValue: oldValue, NewValue: newValue})
+}
+
+func joinPath(prefix string, key string) string {
+	if prefix == "" {
+		return key
+	}
+	return strings.Join([]string{prefix, key}, ".")
+}
@@ -0,0 +1,176 @@
+// Copyright 2024-2025 NetCracker Technology Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+
+	"github.com/Netcracker/qubership-disaster-recovery-daemon/api/entity"
+	"github.com/Netcracker/qubership-disaster-recovery-daemon/internal/usecase"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/tools/cache"
+)
+
+// dry-run requests are queued by the separate key, so they do not replace pending switchover
+const dryRunKeySuffix = "#dry-run"
+
+// WithDryRunFunc sets function which checks whether switchover to the requested mode would succeed without changing
+// anything. The request has DryRun flag set, the verdict is written to DISASTER_RECOVERY_STATUS_DRY_RUN_PATH.
+func (ctr *Controller) WithDryRunFunc(dryRunFunc func(request entity.ControllerRequest) (entity.DryRunResult, error)) *Controller {
+	ctr.dryRunFunc = func(_ context.Context, request entity.ControllerRequest) (entity.DryRunResult, error) {
+		return dryRunFunc(request)
+	}
+	return ctr
+}
+
+// WithDryRunContextFunc sets dry-run function which receives context. The context is cancelled after DRY_RUN_TIMEOUT,
+// since DRD server does not wait for the verdict longer, or when controller is stopping.
+func (ctr *Controller) WithDryRunContextFunc(dryRunFunc func(ctx context.Context, request entity.ControllerRequest) (entity.DryRunResult, error)) *Controller {
+	ctr.dryRunFunc = dryRunFunc
+	return ctr
+}
+
+// handleDryRun puts dry-run request to the queue if DR resource contains request which has no verdict yet.
+func (ctr *Controller) handleDryRun(resource *unstructured.Unstructured, controllerRequest entity.ControllerRequest) {
+	dryRunPath := ctr.config.DisasterRecoveryStatusPath.DryRunPath
+	if len(dryRunPath) == 0 {
+		return
+	}
+	dryRunRequest, ok := getDryRunRequest(resource.Object)
+	if !ok {
+		return
+	}
+	resultID, _, _ := unstructured.NestedString(resource.Object, append(append([]string{}, dryRunPath...), "id")...)
+	if resultID == dryRunRequest.ID {
+		return
+	}
+	key, err := cache.MetaNamespaceKeyFunc(resource)
+	if err != nil {
+		log.Printf("Cannot build key for DR resource: %v", err)
+		return
+	}
+	controllerRequest.Mode = dryRunRequest.Mode
+	controllerRequest.NoWait = &dryRunRequest.NoWait
+	controllerRequest.DryRun = true
+	ctr.enqueue(key+dryRunKeySuffix, controllerRequest, watch.Modified)
+}
+
+// startDryRun executes dry-run request outside of the queue worker, so slow dry-run function does not delay switchover.
+// Only one dry-run is executed at a time, the request received meanwhile is processed when the current one finishes.
+func (ctr *Controller) startDryRun(ctx context.Context, key string, request queuedRequest) {
+	if !ctr.dryRunning.CompareAndSwap(false, true) {
+		return
+	}
+	ctr.dryRuns.Add(1)
+	go func() {
+		defer ctr.dryRuns.Done()
+		ctr.executeDryRun(ctx, request.controllerRequest)
+		ctr.complete(key, request)
+		ctr.dryRunning.Store(false)
+		ctr.mutex.Lock()
+		_, pending := ctr.requests[key]
+		ctr.mutex.Unlock()
+		if pending {
+			ctr.queue.Add(key)
+		}
+	}()
+}
+
+// executeDryRun calls dry-run function and writes its verdict to DR resource status.
+func (ctr *Controller) executeDryRun(ctx context.Context, controllerRequest entity.ControllerRequest) {
+	dryRunRequest, ok := getDryRunRequest(controllerRequest.Object)
+	if !ok || dryRunRequest.ID == ctr.lastDryRunID {
+		return
+	}
+	log.Printf("New incoming dry-run request with mode '%s'", controllerRequest.Mode)
+	result := entity.DryRunResult{Mode: controllerRequest.Mode}
+	if ctr.dryRunFunc == nil {
+		result.Verdict = entity.DryRunFailed
+		result.Comment = "Dry-run function is not set for DR controller"
+	} else {
+		var err error
+		result, err = ctr.callDryRunFunc(ctx, controllerRequest)
+		if ctx.Err() != nil {
+			// verdict is not written, so the request is checked again by the next leader
+			log.Printf("Dry-run is interrupted, verdict is not written: %v", ctx.Err())
+			return
+		}
+		if err != nil {
+			log.Printf("Error occurred during execution of dry-run function: %v", err)
+			result.Verdict = entity.DryRunFailed
+			result.Comment = err.Error()
+		} else if result.Verdict == "" {
+			result.Verdict = entity.DryRunPassed
+		}
+	}
+	result.ID = dryRunRequest.ID
+	result.Mode = controllerRequest.Mode
+	log.Printf("Dry-run finished, verdict: '%s', comment: '%s', findings: %v", result.Verdict, result.Comment, result.Findings)
+	value, err := runtime.DefaultUnstructuredConverter.ToUnstructured(&result)
+	if err != nil {
+		log.Printf("Cannot convert dry-run verdict: %v", err)
+		return
+	}
+	err = ctr.crKubernetesRepo.UpdateStatusFields(ctr.config.DisasterRecoveryStatusPath,
+		entity.StatusField{Path: ctr.config.DisasterRecoveryStatusPath.DryRunPath, Value: value})
+	if err != nil {
+		log.Printf("Cannot update resource status due to: %v", err)
+		return
+	}
+	ctr.lastDryRunID = dryRunRequest.ID
+}
+
+// callDryRunFunc calls dry-run function with DRY_RUN_TIMEOUT deadline. Function which does not return in time
+// is abandoned and the deadline is reported as an error.
+func (ctr *Controller) callDryRunFunc(ctx context.Context, controllerRequest entity.ControllerRequest) (entity.DryRunResult, error) {
+	funcCtx, cancel := context.WithTimeout(ctx, ctr.config.DryRunTimeout)
+	defer cancel()
+	type dryRunResult struct {
+		result entity.DryRunResult
+		err    error
+	}
+	resultCh := make(chan dryRunResult, 1)
+	go func() {
+		result, err := ctr.dryRunFunc(funcCtx, controllerRequest)
+		resultCh <- dryRunResult{result: result, err: err}
+	}()
+	select {
+	case res := <-resultCh:
+		return res.result, res.err
+	case <-funcCtx.Done():
+		if ctx.Err() == nil {
+			log.Printf("Dry-run function has not returned within %v and is abandoned", ctr.config.DryRunTimeout)
+		}
+		return entity.DryRunResult{}, fmt.Errorf("dry-run function has not returned within %v", ctr.config.DryRunTimeout)
+	}
+}
+
+func getDryRunRequest(object map[string]interface{}) (usecase.DryRunRequest, bool) {
+	var dryRunRequest usecase.DryRunRequest
+	annotation, _, _ := unstructured.NestedString(object, "metadata", "annotations", usecase.DryRunAnnotationKey)
+	if annotation == "" {
+		return dryRunRequest, false
+	}
+	if err := json.Unmarshal([]byte(annotation), &dryRunRequest); err != nil {
+		log.Printf("Cannot deserialize dry-run request: %v", err)
+		return dryRunRequest, false
+	}
+	return dryRunRequest, dryRunRequest.ID != ""
+}
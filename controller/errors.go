@@ -0,0 +1,101 @@
+// Copyright 2024-2025 NetCracker Technology Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrLeadershipLost is a cause of DR function context cancellation when controller replica loses leadership.
+// The switchover is left to the new leader, so its status is not written by this replica.
+var ErrLeadershipLost = errors.New("DR controller is not a leader anymore")
+
+// TimeoutError is returned when DR function does not finish switchover within configured timeout.
+type TimeoutError struct {
+	Timeout time.Duration
+}
+
+func (e *TimeoutError) Error() string {
+	return fmt.Sprintf("switchover was not finished within %v timeout", e.Timeout)
+}
+
+// CancelledError is a cause of DR function context cancellation when switchover is cancelled via DRD server.
+type CancelledError struct {
+	Caller string
+}
+
+func (e *CancelledError) Error() string {
+	return fmt.Sprintf("cancelled by %s", e.Caller)
+}
+
+// PermanentError marks DR function error which cannot be fixed by retry.
+type PermanentError struct {
+	Err error
+}
+
+func (e *PermanentError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *PermanentError) Unwrap() error {
+	return e.Err
+}
+
+// Permanent wraps DR function error, so the switchover is marked as failed without retries.
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &PermanentError{Err: err}
+}
+
+// notCalledError is returned when DR function is not called, e.g. DR resource cannot be decoded. The service
+// is not changed in this case, so the switchover is marked as failed without rollback.
+type notCalledError struct {
+	err error
+}
+
+func (e *notCalledError) Error() string {
+	return e.err.Error()
+}
+
+func (e *notCalledError) Unwrap() error {
+	return e.err
+}
+
+// RetryAfterError marks DR function error which should be retried after the given delay instead of backoff delay.
+type RetryAfterError struct {
+	Err   error
+	Delay time.Duration
+}
+
+func (e *RetryAfterError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *RetryAfterError) Unwrap() error {
+	return e.Err
+}
+
+// RetryAfter wraps DR function error, so the next attempt is performed after the delay.
+// The number of attempts is still limited by retry policy.
+func RetryAfter(err error, delay time.Duration) error {
+	if err == nil {
+		return nil
+	}
+	return &RetryAfterError{Err: err, Delay: delay}
+}
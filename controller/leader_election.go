@@ -0,0 +1,122 @@
+// Copyright 2024-2025 NetCracker Technology Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/Netcracker/qubership-disaster-recovery-daemon/api/entity"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/leaderelection"
+	"k8s.io/client-go/tools/leaderelection/resourcelock"
+)
+
+type leaderElectionConfig struct {
+	leaseName     string
+	namespace     string
+	leaseDuration time.Duration
+	renewDeadline time.Duration
+	retryPeriod   time.Duration
+}
+
+// WithLeaderElection enables Lease based leader election, so only one of controller replicas executes DR function.
+// Other replicas keep watching DR resource and take over switchovers when the leader is lost.
+func (ctr *Controller) WithLeaderElection(leaseName string, namespace string,
+	leaseDuration time.Duration, renewDeadline time.Duration, retryPeriod time.Duration) *Controller {
+	ctr.leaderElection = &leaderElectionConfig{
+		leaseName:     leaseName,
+		namespace:     namespace,
+		leaseDuration: leaseDuration,
+		renewDeadline: renewDeadline,
+		retryPeriod:   retryPeriod,
+	}
+	return ctr
+}
+
+func (ctr *Controller) isLeader() bool {
+	return ctr.leaderElection == nil || ctr.leader.Load()
+}
+
+func (ctr *Controller) buildLeaderElector(kubeClient kubernetes.Interface,
+	informer cache.SharedIndexInformer) (*leaderelection.LeaderElector, error) {
+	identity := ctr.identity
+	lock := &resourcelock.LeaseLock{
+		LeaseMeta: metav1.ObjectMeta{
+			Name:      ctr.leaderElection.leaseName,
+			Namespace: ctr.leaderElection.namespace,
+		},
+		Client:     kubeClient.CoordinationV1(),
+		LockConfig: resourcelock.ResourceLockConfig{Identity: identity},
+	}
+	return leaderelection.NewLeaderElector(leaderelection.LeaderElectionConfig{
+		Lock:            lock,
+		Name:            ctr.leaderElection.leaseName,
+		LeaseDuration:   ctr.leaderElection.leaseDuration,
+		RenewDeadline:   ctr.leaderElection.renewDeadline,
+		RetryPeriod:     ctr.leaderElection.retryPeriod,
+		ReleaseOnCancel: true,
+		Callbacks: leaderelection.LeaderCallbacks{
+			OnStartedLeading: func(leaderCtx context.Context) {
+				ctr.leader.Store(true)
+				log.Printf("Controller '%s' became a leader", identity)
+				err := ctr.crKubernetesRepo.UpdateStatusFields(ctr.config.DisasterRecoveryStatusPath,
+					entity.StatusField{Path: ctr.config.DisasterRecoveryStatusPath.LeaderPath, Value: identity})
+				if err != nil {
+					log.Printf("Error: Cannot update resource status due to: %v", err)
+				}
+				// events received while this replica was a follower were skipped, so current resource state is processed again,
+				// switchover which was in progress on the previous leader is recovered
+				ctr.startRecovery()
+				if cache.WaitForCacheSync(leaderCtx.Done(), informer.HasSynced) {
+					for _, obj := range informer.GetStore().List() {
+						ctr.handleEvent(nil, obj, watch.Added)
+					}
+				}
+			},
+			OnStoppedLeading: ctr.stopLeading,
+			OnNewLeader: func(leaderIdentity string) {
+				log.Printf("Current DR controller leader is '%s'", leaderIdentity)
+			},
+		},
+	})
+}
+
+// stopLeading makes the replica a follower and cancels DR function which is running on it, so the switchover
+// is not executed by two replicas when the new leader recovers it.
+func (ctr *Controller) stopLeading() {
+	ctr.leader.Store(false)
+	log.Printf("Controller '%s' is not a leader anymore", ctr.identity)
+	ctr.mutex.Lock()
+	cancelRunning := ctr.cancelRunning
+	ctr.mutex.Unlock()
+	if cancelRunning != nil {
+		cancelRunning(ErrLeadershipLost)
+	}
+}
+
+// runLeaderElector campaigns for leadership until controller is stopped. Elector stops when leadership is lost,
+// so the replica campaigns again after the retry period, which keeps the API server from a tight loop of retries.
+func (ctr *Controller) runLeaderElector(ctx context.Context, elector *leaderelection.LeaderElector) {
+	wait.UntilWithContext(ctx, func(ctx context.Context) {
+		log.Printf("Controller '%s' is campaigning for leadership", ctr.identity)
+		elector.Run(ctx)
+	}, ctr.leaderElection.retryPeriod)
+}
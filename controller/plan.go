@@ -0,0 +1,284 @@
+// Copyright 2024-2025 NetCracker Technology Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/Netcracker/qubership-disaster-recovery-daemon/api/entity"
+	batchv1 "k8s.io/api/batch/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"sigs.k8s.io/yaml"
+)
+
+// Types of switchover plan steps
+const (
+	ScaleStep     = "scale"
+	PatchStep     = "patch"
+	WaitReadyStep = "waitReady"
+	HTTPStep      = "http"
+	JobStep       = "job"
+)
+
+const defaultStepPollInterval = 5 * time.Second
+
+// Plan is a list of steps which are executed in order to switch service to the mode.
+type Plan struct {
+	Steps []PlanStep `json:"steps"`
+}
+
+// PlanStep is one action of switchover plan. Only the field which corresponds to the step type is used.
+// Failed step is retried Retries times with RetryDelay, Timeout limits every attempt, zero Timeout means no limit.
+type PlanStep struct {
+	Name       string          `json:"name"`
+	Type       string          `json:"type"`
+	Timeout    metav1.Duration `json:"timeout,omitempty"`
+	Retries    int             `json:"retries,omitempty"`
+	RetryDelay metav1.Duration `json:"retryDelay,omitempty"`
+	Scale      *ScaleAction    `json:"scale,omitempty"`
+	Patch      *PatchAction    `json:"patch,omitempty"`
+	WaitReady  *WaitAction     `json:"waitReady,omitempty"`
+	HTTP       *HTTPAction     `json:"http,omitempty"`
+	Job        *JobAction      `json:"job,omitempty"`
+}
+
+// ScaleAction sets the number of replicas of Deployment or StatefulSet.
+type ScaleAction struct {
+	Kind      string `json:"kind"`
+	Name      string `json:"name"`
+	Namespace string `json:"namespace,omitempty"`
+	Replicas  int32  `json:"replicas"`
+}
+
+// PatchAction patches any resource with merge (default) or json patch.
+type PatchAction struct {
+	Group     string `json:"group,omitempty"`
+	Version   string `json:"version"`
+	Resource  string `json:"resource"`
+	Name      string `json:"name"`
+	Namespace string `json:"namespace,omitempty"`
+	PatchType string `json:"patchType,omitempty"`
+	Patch     string `json:"patch"`
+}
+
+// WaitAction waits until all replicas of Deployment or StatefulSet are ready.
+type WaitAction struct {
+	Kind      string          `json:"kind"`
+	Name      string          `json:"name"`
+	Namespace string          `json:"namespace,omitempty"`
+	Interval  metav1.Duration `json:"interval,omitempty"`
+}
+
+// HTTPAction calls HTTP endpoint. The step fails if response code is not in ExpectedStatus, any 2xx code
+// is expected by default.
+type HTTPAction struct {
+	Method         string            `json:"method,omitempty"`
+	URL            string            `json:"url"`
+	Headers        map[string]string `json:"headers,omitempty"`
+	Body           string            `json:"body,omitempty"`
+	ExpectedStatus []int             `json:"expectedStatus,omitempty"`
+}
+
+// JobAction runs Job and waits for its completion.
+type JobAction struct {
+	Name      string          `json:"name"`
+	Namespace string          `json:"namespace,omitempty"`
+	Spec      batchv1.JobSpec `json:"spec"`
+	Interval  metav1.Duration `json:"interval,omitempty"`
+}
+
+// ParsePlans reads plans from config map data, the key is the mode and the value is YAML plan.
+func ParsePlans(data map[string]string) (map[string]Plan, error) {
+	plans := make(map[string]Plan, len(data))
+	for mode, planYaml := range data {
+		var plan Plan
+		if err := yaml.UnmarshalStrict([]byte(planYaml), &plan); err != nil {
+			return nil, fmt.Errorf("cannot parse switchover plan for '%s' mode: %w", mode, err)
+		}
+		if err := plan.validate(); err != nil {
+			return nil, fmt.Errorf("switchover plan for '%s' mode is invalid: %w", mode, err)
+		}
+		plans[mode] = plan
+	}
+	return plans, nil
+}
+
+func (plan Plan) validate() error {
+	for i, step := range plan.Steps {
+		if step.Name == "" {
+			return fmt.Errorf("step %d has no name", i+1)
+		}
+		var action interface{}
+		switch step.Type {
+		case ScaleStep:
+			action = step.Scale
+		case PatchStep:
+			action = step.Patch
+		case WaitReadyStep:
+			action = step.WaitReady
+		case HTTPStep:
+			action = step.HTTP
+		case JobStep:
+			action = step.Job
+		default:
+			return fmt.Errorf("step '%s' has unknown type '%s'", step.Name, step.Type)
+		}
+		if isNilAction(action) {
+			return fmt.Errorf("step '%s' has no '%s' section", step.Name, step.Type)
+		}
+	}
+	return nil
+}
+
+func isNilAction(action interface{}) bool {
+	switch a := action.(type) {
+	case *ScaleAction:
+		return a == nil
+	case *PatchAction:
+		return a == nil
+	case *WaitAction:
+		return a == nil
+	case *HTTPAction:
+		return a == nil
+	case *JobAction:
+		return a == nil
+	}
+	return true
+}
+
+// WithPlanConfigMap sets DR function which executes declarative switchover plan from the config map in DR resource
+// namespace. The config map is read on every switchover, so plans can be changed without controller restart.
+func (ctr *Controller) WithPlanConfigMap(name string) *Controller {
+	return ctr.WithContextFunc(func(ctx context.Context, request entity.ControllerRequest) (entity.ControllerResponse, error) {
+		configMap, err := ctr.kubeClient.CoreV1().ConfigMaps(ctr.config.Namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return entity.ControllerResponse{}, fmt.Errorf("cannot read switchover plans from '%s' config map: %w", name, err)
+		}
+		plans, err := ParsePlans(configMap.Data)
+		if err != nil {
+			return entity.ControllerResponse{}, Permanent(err)
+		}
+		return ctr.executePlan(ctx, plans, request)
+	})
+}
+
+// WithPlans sets DR function which executes the given declarative switchover plans, the key is the mode.
+func (ctr *Controller) WithPlans(plans map[string]Plan) *Controller {
+	return ctr.WithContextFunc(func(ctx context.Context, request entity.ControllerRequest) (entity.ControllerResponse, error) {
+		return ctr.executePlan(ctx, plans, request)
+	})
+}
+
+func (ctr *Controller) executePlan(ctx context.Context, plans map[string]Plan,
+	request entity.ControllerRequest) (entity.ControllerResponse, error) {
+	plan, ok := plans[request.Mode]
+	if !ok {
+		return entity.ControllerResponse{}, Permanent(fmt.Errorf("there is no switchover plan for '%s' mode", request.Mode))
+	}
+	executor := planExecutor{kubeClient: ctr.kubeClient, dynClient: ctr.dynClient, namespace: ctr.config.Namespace}
+	for i, step := range plan.Steps {
+		if request.Progress != nil {
+			request.Progress.Report(step.Name, i*100/len(plan.Steps), "")
+		}
+		if err := executor.executeStep(ctx, step); err != nil {
+			if ctx.Err() != nil {
+				return entity.ControllerResponse{}, err
+			}
+			return entity.ControllerResponse{}, Permanent(fmt.Errorf("switchover step '%s' failed: %w", step.Name, err))
+		}
+	}
+	return entity.ControllerResponse{
+		SwitchoverState: entity.SwitchoverState{
+			Mode:    request.Mode,
+			Status:  entity.DONE,
+			Comment: fmt.Sprintf("Switchover plan of %d steps is finished", len(plan.Steps)),
+		},
+	}, nil
+}
+
+type planExecutor struct {
+	kubeClient kubernetes.Interface
+	dynClient  dynamic.Interface
+	namespace  string
+}
+
+// executeStep runs the step with its retries, every attempt is limited by the step timeout.
+func (pe planExecutor) executeStep(ctx context.Context, step PlanStep) error {
+	var err error
+	for attempt := 0; attempt <= step.Retries; attempt++ {
+		if attempt > 0 {
+			log.Printf("Attempt %d of switchover step '%s' failed, retry will be performed in %v: %v",
+				attempt, step.Name, step.RetryDelay.Duration, err)
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(step.RetryDelay.Duration):
+			}
+		}
+		err = pe.executeAttempt(ctx, step)
+		if err == nil || ctx.Err() != nil {
+			return err
+		}
+	}
+	return err
+}
+
+func (pe planExecutor) executeAttempt(ctx context.Context, step PlanStep) error {
+	if step.Timeout.Duration > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, step.Timeout.Duration)
+		defer cancel()
+	}
+	log.Printf("Executing switchover step '%s' of '%s' type", step.Name, step.Type)
+	var err error
+	switch step.Type {
+	case ScaleStep:
+		err = pe.scale(ctx, step.Scale)
+	case PatchStep:
+		err = pe.patch(ctx, step.Patch)
+	case WaitReadyStep:
+		err = pe.waitReady(ctx, step.WaitReady)
+	case HTTPStep:
+		err = pe.call(ctx, step.HTTP)
+	case JobStep:
+		err = pe.runJob(ctx, step.Job)
+	default:
+		err = fmt.Errorf("unknown step type '%s'", step.Type)
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return fmt.Errorf("step was not finished within %v timeout: %w", step.Timeout.Duration, err)
+	}
+	return err
+}
+
+func (pe planExecutor) namespaceOrDefault(namespace string) string {
+	if namespace == "" {
+		return pe.namespace
+	}
+	return namespace
+}
+
+func pollInterval(interval metav1.Duration) time.Duration {
+	if interval.Duration <= 0 {
+		return defaultStepPollInterval
+	}
+	return interval.Duration
+}
@@ -0,0 +1,215 @@
+// Copyright 2024-2025 NetCracker Technology Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+const (
+	deploymentKind  = "Deployment"
+	statefulSetKind = "StatefulSet"
+	// finished Jobs are kept for an hour for troubleshooting unless the step specifies its own TTL
+	jobTTLSecondsAfterFinished = int32(3600)
+)
+
+func (pe planExecutor) scale(ctx context.Context, action *ScaleAction) error {
+	namespace := pe.namespaceOrDefault(action.Namespace)
+	switch action.Kind {
+	case deploymentKind:
+		deployments := pe.kubeClient.AppsV1().Deployments(namespace)
+		deployment, err := deployments.Get(ctx, action.Name, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		deployment.Spec.Replicas = &action.Replicas
+		_, err = deployments.Update(ctx, deployment, metav1.UpdateOptions{})
+		return err
+	case statefulSetKind:
+		statefulSets := pe.kubeClient.AppsV1().StatefulSets(namespace)
+		statefulSet, err := statefulSets.Get(ctx, action.Name, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		statefulSet.Spec.Replicas = &action.Replicas
+		_, err = statefulSets.Update(ctx, statefulSet, metav1.UpdateOptions{})
+		return err
+	}
+	return fmt.Errorf("cannot scale '%s', only %s and %s are supported", action.Kind, deploymentKind, statefulSetKind)
+}
+
+func (pe planExecutor) patch(ctx context.Context, action *PatchAction) error {
+	patchType := types.MergePatchType
+	switch strings.ToLower(action.PatchType) {
+	case "", "merge":
+	case "json":
+		patchType = types.JSONPatchType
+	default:
+		return fmt.Errorf("unknown patch type '%s', only merge and json are supported", action.PatchType)
+	}
+	resource := schema.GroupVersionResource{Group: action.Group, Version: action.Version, Resource: action.Resource}
+	_, err := pe.dynClient.Resource(resource).Namespace(pe.namespaceOrDefault(action.Namespace)).
+		Patch(ctx, action.Name, patchType, []byte(action.Patch), metav1.PatchOptions{})
+	return err
+}
+
+func (pe planExecutor) waitReady(ctx context.Context, action *WaitAction) error {
+	namespace := pe.namespaceOrDefault(action.Namespace)
+	var isReady func() (bool, error)
+	switch action.Kind {
+	case deploymentKind:
+		isReady = func() (bool, error) {
+			deployment, err := pe.kubeClient.AppsV1().Deployments(namespace).Get(ctx, action.Name, metav1.GetOptions{})
+			if err != nil {
+				return false, err
+			}
+			return deployment.Status.ObservedGeneration >= deployment.Generation &&
+				deployment.Status.ReadyReplicas == replicas(deployment.Spec.Replicas) &&
+				deployment.Status.UpdatedReplicas == replicas(deployment.Spec.Replicas), nil
+		}
+	case statefulSetKind:
+		isReady = func() (bool, error) {
+			statefulSet, err := pe.kubeClient.AppsV1().StatefulSets(namespace).Get(ctx, action.Name, metav1.GetOptions{})
+			if err != nil {
+				return false, err
+			}
+			return isStatefulSetReady(statefulSet), nil
+		}
+	default:
+		return fmt.Errorf("cannot wait for '%s', only %s and %s are supported", action.Kind, deploymentKind, statefulSetKind)
+	}
+	return poll(ctx, pollInterval(action.Interval), func() (bool, error) {
+		ready, err := isReady()
+		if err == nil && !ready {
+			log.Printf("%s '%s' is not ready yet", action.Kind, action.Name)
+		}
+		return ready, err
+	})
+}
+
+// isStatefulSetReady checks that the rollout is finished in the same way as kubectl rollout status: with partitioned
+// rolling update only replicas above the partition must be updated, otherwise all replicas must run the update revision.
+func isStatefulSetReady(statefulSet *appsv1.StatefulSet) bool {
+	want := replicas(statefulSet.Spec.Replicas)
+	if statefulSet.Status.ObservedGeneration < statefulSet.Generation || statefulSet.Status.ReadyReplicas != want {
+		return false
+	}
+	if rollingUpdate := statefulSet.Spec.UpdateStrategy.RollingUpdate; rollingUpdate != nil &&
+		rollingUpdate.Partition != nil && *rollingUpdate.Partition > 0 {
+		return statefulSet.Status.UpdatedReplicas >= want-*rollingUpdate.Partition
+	}
+	return statefulSet.Status.UpdatedReplicas == want &&
+		statefulSet.Status.CurrentRevision == statefulSet.Status.UpdateRevision
+}
+
+func replicas(specReplicas *int32) int32 {
+	if specReplicas == nil {
+		return 1
+	}
+	return *specReplicas
+}
+
+func (pe planExecutor) call(ctx context.Context, action *HTTPAction) error {
+	method := action.Method
+	if method == "" {
+		method = http.MethodGet
+	}
+	request, err := http.NewRequestWithContext(ctx, method, action.URL, strings.NewReader(action.Body))
+	if err != nil {
+		return err
+	}
+	for name, value := range action.Headers {
+		request.Header.Set(name, value)
+	}
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	body, _ := io.ReadAll(io.LimitReader(response.Body, 1024))
+	if len(action.ExpectedStatus) == 0 && response.StatusCode/100 == 2 || slices.Contains(action.ExpectedStatus, response.StatusCode) {
+		return nil
+	}
+	return fmt.Errorf("%s %s returned unexpected status %d: %s", method, action.URL, response.StatusCode, string(body))
+}
+
+func (pe planExecutor) runJob(ctx context.Context, action *JobAction) error {
+	namespace := pe.namespaceOrDefault(action.Namespace)
+	job := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{GenerateName: action.Name + "-", Namespace: namespace},
+		Spec:       *action.Spec.DeepCopy(),
+	}
+	if job.Spec.Template.Spec.RestartPolicy == "" {
+		job.Spec.Template.Spec.RestartPolicy = corev1.RestartPolicyNever
+	}
+	if job.Spec.TTLSecondsAfterFinished == nil {
+		ttl := jobTTLSecondsAfterFinished
+		job.Spec.TTLSecondsAfterFinished = &ttl
+	}
+	job, err := pe.kubeClient.BatchV1().Jobs(namespace).Create(ctx, job, metav1.CreateOptions{})
+	if err != nil {
+		return err
+	}
+	log.Printf("Job '%s' is created", job.Name)
+	return poll(ctx, pollInterval(action.Interval), func() (bool, error) {
+		job, err := pe.kubeClient.BatchV1().Jobs(namespace).Get(ctx, job.Name, metav1.GetOptions{})
+		if err != nil {
+			return false, err
+		}
+		for _, condition := range job.Status.Conditions {
+			if condition.Status != corev1.ConditionTrue {
+				continue
+			}
+			switch condition.Type {
+			case batchv1.JobComplete:
+				return true, nil
+			case batchv1.JobFailed:
+				return false, fmt.Errorf("job '%s' failed: %s", job.Name, condition.Message)
+			}
+		}
+		return false, nil
+	})
+}
+
+// poll calls condition until it is true or returns an error, or the context is done.
+func poll(ctx context.Context, interval time.Duration, condition func() (bool, error)) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		done, err := condition()
+		if err != nil || done {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
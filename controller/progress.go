@@ -0,0 +1,66 @@
+// Copyright 2024-2025 NetCracker Technology Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+
+	"github.com/Netcracker/qubership-disaster-recovery-daemon/api/entity"
+	"github.com/Netcracker/qubership-disaster-recovery-daemon/config"
+	"github.com/Netcracker/qubership-disaster-recovery-daemon/internal/usecase"
+)
+
+type statusProgressReporter struct {
+	ctx              context.Context
+	crKubernetesRepo usecase.KubernetesCustomResourceRepo
+	statusPath       config.DisasterRecoveryStatusPath
+	mode             string
+	finished         bool
+	mutex            sync.Mutex
+}
+
+// Report keeps switchover in running status and puts the step and its progress to the status comment.
+// Reports are ignored when DR function context is cancelled or the function has returned, so they do not
+// overwrite the final status.
+func (spr *statusProgressReporter) Report(step string, percent int, comment string) {
+	spr.mutex.Lock()
+	defer spr.mutex.Unlock()
+	if spr.finished || spr.ctx.Err() != nil {
+		return
+	}
+	progressComment := fmt.Sprintf("Switchover step '%s' is in progress (%d%%)", step, percent)
+	if comment != "" {
+		progressComment = fmt.Sprintf("%s: %s", progressComment, comment)
+	}
+	log.Println(progressComment)
+	err := spr.crKubernetesRepo.UpdateStatus(spr.statusPath, entity.SwitchoverState{
+		Mode:    spr.mode,
+		Status:  entity.RUNNING,
+		Comment: progressComment,
+	})
+	if err != nil {
+		log.Printf("Error: Cannot update resource status due to: %v", err)
+	}
+}
+
+// finish stops accepting reports, it waits for the report which is being written.
+func (spr *statusProgressReporter) finish() {
+	spr.mutex.Lock()
+	spr.finished = true
+	spr.mutex.Unlock()
+}
@@ -0,0 +1,117 @@
+// Copyright 2024-2025 NetCracker Technology Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/Netcracker/qubership-disaster-recovery-daemon/api/entity"
+	"github.com/Netcracker/qubership-disaster-recovery-daemon/config"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// staleQueueAge is the age of queued switchover after which it is considered left by the previous controller,
+// switchover which was queued recently just waits for the controller to start.
+const staleQueueAge = 5 * time.Minute
+
+// WithStaleSwitchoverPolicy sets how switchover which was left in 'running' or 'queue' status by previous controller
+// is handled: config.ResumeStaleSwitchover re-invokes DR function, config.FailStaleSwitchover marks it as failed.
+// It overrides STALE_SWITCHOVER_POLICY environment variable.
+func (ctr *Controller) WithStaleSwitchoverPolicy(policy string) *Controller {
+	ctr.stalePolicy = policy
+	return ctr
+}
+
+// failStaleSwitchover checks whether the request belongs to switchover interrupted by controller restart or leader change.
+// It returns true if the switchover has been marked as failed according to the policy and must not be executed.
+func (ctr *Controller) failStaleSwitchover(controllerRequest entity.ControllerRequest) bool {
+	status := controllerRequest.Status.Status
+	if status != entity.RUNNING && status != entity.QUEUE {
+		return false
+	}
+	description := fmt.Sprintf("Switchover in '%s' status", status)
+	statusPath := ctr.config.DisasterRecoveryStatusPath
+	if owner := nestedString(controllerRequest.Object, statusPath.OwnerPath); owner != "" {
+		description = fmt.Sprintf("%s started by '%s'", description, owner)
+	}
+	if startTime := nestedString(controllerRequest.Object, statusPath.StartTimePath); startTime != "" {
+		description = fmt.Sprintf("%s at %s", description, startTime)
+	}
+	if !ctr.isStaleSwitchover(controllerRequest) {
+		log.Printf("%s is not stale, it will be processed", description)
+		ctr.resourceVersion = ""
+		return false
+	}
+	description = fmt.Sprintf("%s was interrupted by DR controller restart", description)
+
+	if ctr.stalePolicy != config.FailStaleSwitchover {
+		log.Printf("%s, it will be resumed", description)
+		ctr.resourceVersion = ""
+		return false
+	}
+	log.Println(description)
+	err := ctr.crKubernetesRepo.UpdateStatus(statusPath, entity.SwitchoverState{
+		Mode:    controllerRequest.Mode,
+		Status:  entity.FAILED,
+		Comment: description,
+	})
+	if err != nil {
+		log.Printf("Error: Cannot update resource status due to: %v", err)
+	}
+	if err := ctr.updateResourceVersion(ctr.crKubernetesRepo); err != nil {
+		log.Printf("Error: Cannot update resource status due to: %v", err)
+	}
+	ctr.recordEvent(controllerRequest.Object, corev1.EventTypeWarning, entity.SwitchoverFailedReason, "%s", description)
+	return true
+}
+
+// startRecovery makes the controller check the first processed state of DR resource for stale switchover.
+func (ctr *Controller) startRecovery() {
+	ctr.recoveryStarted.Store(time.Now().UnixNano())
+	ctr.recoveryPending.Store(true)
+}
+
+// isStaleSwitchover decides by the owner and the start time of switchover whether it was left by another controller or
+// by the previous run of this one. Switchover is considered stale by its status only if they are not written,
+// see DISASTER_RECOVERY_STATUS_OWNER_PATH and DISASTER_RECOVERY_STATUS_START_TIME_PATH.
+func (ctr *Controller) isStaleSwitchover(controllerRequest entity.ControllerRequest) bool {
+	statusPath := ctr.config.DisasterRecoveryStatusPath
+	owner := nestedString(controllerRequest.Object, statusPath.OwnerPath)
+	startTime, err := time.Parse(time.RFC3339, nestedString(controllerRequest.Object, statusPath.StartTimePath))
+	if err != nil {
+		return true
+	}
+	recoveryStarted := time.Unix(0, ctr.recoveryStarted.Load())
+	switch controllerRequest.Status.Status {
+	case entity.RUNNING:
+		// owner and start time are written by controller which starts DR function
+		return owner != ctr.identity || startTime.Before(recoveryStarted.Truncate(time.Second))
+	case entity.QUEUE:
+		// start time is written by DRD server when switchover is queued
+		return recoveryStarted.Sub(startTime) > staleQueueAge
+	}
+	return false
+}
+
+func nestedString(object map[string]interface{}, path []string) string {
+	if len(path) == 0 {
+		return ""
+	}
+	value, _, _ := unstructured.NestedString(object, path...)
+	return value
+}
@@ -0,0 +1,79 @@
+// Copyright 2024-2025 NetCracker Technology Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/Netcracker/qubership-disaster-recovery-daemon/api/entity"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// WithRollbackFunc sets function which returns service to the previous mode when DR function failed or returned failed status
+// and no more retries are left. The request contains the previous mode, the error is the failure of DR function.
+func (ctr *Controller) WithRollbackFunc(rollbackFunc func(request entity.ControllerRequest, err error) (entity.ControllerResponse, error)) *Controller {
+	ctr.rollbackFunc = rollbackFunc
+	return ctr
+}
+
+// rollback calls rollback function for failed switchover and returns the status comment
+// and whether service was returned to the previous mode.
+func (ctr *Controller) rollback(controllerRequest entity.ControllerRequest, cause error, comment string) (string, bool) {
+	previousMode := ctr.previousMode(controllerRequest)
+	if previousMode == "" || previousMode == controllerRequest.Mode {
+		log.Printf("Previous DR mode is unknown, rollback of switchover to '%s' mode is skipped", controllerRequest.Mode)
+		return comment, false
+	}
+	log.Printf("Switchover to '%s' mode failed, rolling back to '%s' mode", controllerRequest.Mode, previousMode)
+	rollbackRequest := controllerRequest
+	rollbackRequest.Mode = previousMode
+	rollbackRequest.Progress = nil
+	rollbackResponse, err := ctr.rollbackFunc(rollbackRequest, cause)
+	if err == nil && rollbackResponse.Status == entity.FAILED {
+		err = fmt.Errorf("%s", rollbackResponse.Comment)
+	}
+	if err != nil {
+		log.Printf("Error occurred during rollback to '%s' mode: %v", previousMode, err)
+		ctr.recordEvent(controllerRequest.Object, corev1.EventTypeWarning, entity.SwitchoverRollbackFailedReason,
+			"Rollback to '%s' mode failed: %v", previousMode, err)
+		return fmt.Sprintf("%s; rollback to '%s' mode failed: %v", comment, previousMode, err), false
+	}
+	ctr.recordEvent(controllerRequest.Object, corev1.EventTypeNormal, entity.SwitchoverRolledBackReason,
+		"Service was rolled back to '%s' mode", previousMode)
+	comment = fmt.Sprintf("%s; rolled back to '%s' mode", comment, previousMode)
+	if rollbackResponse.Comment != "" {
+		comment = fmt.Sprintf("%s: %s", comment, rollbackResponse.Comment)
+	}
+	return comment, true
+}
+
+// previousMode returns the mode before switchover, it is taken from the latest history record if history is kept,
+// otherwise from the status which was observed when switchover was requested.
+func (ctr *Controller) previousMode(controllerRequest entity.ControllerRequest) string {
+	historyPath := ctr.config.DisasterRecoveryStatusPath.HistoryPath
+	if len(historyPath) > 0 {
+		history, _, _ := unstructured.NestedSlice(controllerRequest.Object, historyPath...)
+		if len(history) > 0 {
+			if latest, ok := history[0].(map[string]interface{}); ok {
+				if mode, ok := latest["previousMode"].(string); ok && mode != "" {
+					return mode
+				}
+			}
+		}
+	}
+	return controllerRequest.Status.Mode
+}
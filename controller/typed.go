@@ -0,0 +1,78 @@
+// Copyright 2024-2025 NetCracker Technology Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/Netcracker/qubership-disaster-recovery-daemon/api/entity"
+	"github.com/Netcracker/qubership-disaster-recovery-daemon/config"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// TypedRequest is a controller request with DR resource decoded into T. OldResource is nil
+// if there is no previous state of DR resource, e.g. for the first event after controller start.
+type TypedRequest[T any] struct {
+	entity.ControllerRequest
+	Resource    T
+	OldResource *T
+}
+
+// TypedController is a controller which decodes DR resource into T before calling DR function.
+// All other options of Controller can be used after the function is set.
+type TypedController[T any] struct {
+	*Controller
+}
+
+func NewTypedController[T any](config *config.Config) *TypedController[T] {
+	return &TypedController[T]{Controller: NewController(config)}
+}
+
+// WithFunc sets DR function which receives decoded DR resource. If DR resource cannot be decoded,
+// the function is not called and switchover is marked as failed without retries and rollback.
+func (ctr *TypedController[T]) WithFunc(controllerFunc func(request TypedRequest[T]) (entity.ControllerResponse, error)) *Controller {
+	return ctr.WithContextFunc(func(_ context.Context, request TypedRequest[T]) (entity.ControllerResponse, error) {
+		return controllerFunc(request)
+	})
+}
+
+// WithContextFunc sets DR function which receives context and decoded DR resource.
+func (ctr *TypedController[T]) WithContextFunc(controllerFunc func(ctx context.Context, request TypedRequest[T]) (entity.ControllerResponse, error)) *Controller {
+	return ctr.Controller.WithContextFunc(func(ctx context.Context, request entity.ControllerRequest) (entity.ControllerResponse, error) {
+		typedRequest, err := decodeTypedRequest[T](request)
+		if err != nil {
+			log.Printf("DR function is not called: %v", err)
+			return entity.ControllerResponse{}, Permanent(&notCalledError{err: err})
+		}
+		return controllerFunc(ctx, typedRequest)
+	})
+}
+
+func decodeTypedRequest[T any](request entity.ControllerRequest) (TypedRequest[T], error) {
+	typedRequest := TypedRequest[T]{ControllerRequest: request}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(request.Object, &typedRequest.Resource); err != nil {
+		return TypedRequest[T]{}, fmt.Errorf("cannot decode DR resource: %w", err)
+	}
+	if request.OldObject != nil {
+		var oldResource T
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(request.OldObject, &oldResource); err != nil {
+			return TypedRequest[T]{}, fmt.Errorf("cannot decode previous state of DR resource: %w", err)
+		}
+		typedRequest.OldResource = &oldResource
+	}
+	return typedRequest, nil
+}
@@ -0,0 +1,378 @@
+// Copyright 2024-2025 NetCracker Technology Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package drdtest provides an in-memory Kubernetes cluster with DR resource, so DR functions can be tested
+// end-to-end with the real DR controller and without a cluster.
+package drdtest
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"slices"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Netcracker/qubership-disaster-recovery-daemon/api/entity"
+	"github.com/Netcracker/qubership-disaster-recovery-daemon/config"
+	"github.com/Netcracker/qubership-disaster-recovery-daemon/controller"
+	"github.com/Netcracker/qubership-disaster-recovery-daemon/internal/usecase"
+	"github.com/Netcracker/qubership-disaster-recovery-daemon/internal/usecase/repo"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/watch"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+const (
+	pollInterval = 10 * time.Millisecond
+	startTimeout = 10 * time.Second
+)
+
+// Cluster keeps DR resource in fake Kubernetes clients and records every change of its switchover status.
+// Fake clients can be passed to the server and controller under test.
+type Cluster struct {
+	Config        *config.Config
+	DynamicClient *dynamicfake.FakeDynamicClient
+	KubeClient    *kubefake.Clientset
+
+	t               testing.TB
+	gvr             schema.GroupVersionResource
+	crRepo          *repo.KubernetesCustomResourceRepo
+	resourceVersion atomic.Int64
+	transitions     []entity.SwitchoverState
+	mutex           sync.Mutex
+	eventRecorder   *repo.KubernetesEventRecorder
+	ctx             context.Context
+}
+
+// NewCluster creates fake cluster with the given DR resource. Recording of status transitions
+// is stopped when the test finishes.
+func NewCluster(t testing.TB, cfg *config.Config, resource *unstructured.Unstructured) *Cluster {
+	t.Helper()
+	gvr := schema.GroupVersionResource{Group: cfg.Group, Version: cfg.Version, Resource: cfg.Resource}
+	resource = resource.DeepCopy()
+	cluster := &Cluster{
+		Config: cfg,
+		DynamicClient: dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+			map[schema.GroupVersionResource]string{gvr: resource.GetKind() + "List"}),
+		KubeClient: kubefake.NewSimpleClientset(),
+		t:          t,
+		gvr:        gvr,
+	}
+	cluster.crRepo = repo.NewKubernetesCustomResourceRepo(cluster.DynamicClient, gvr, cfg.Name, cfg.Namespace)
+	// fake clients neither maintain resource version and generation nor generate names, but DRD relies on them
+	cluster.DynamicClient.PrependReactor("*", "*", cluster.setResourceVersion)
+	cluster.DynamicClient.PrependReactor("update", "*", cluster.setGeneration)
+	cluster.KubeClient.PrependReactor("create", "*", cluster.generateName)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cluster.ctx = ctx
+	cluster.eventRecorder = repo.NewKubernetesEventRecorder(ctx, cluster.KubeClient, "disaster-recovery-server")
+	watcher, err := cluster.DynamicClient.Resource(gvr).Namespace(cfg.Namespace).Watch(ctx, metav1.ListOptions{})
+	if err != nil {
+		t.Fatalf("Cannot watch DR resource: %v", err)
+	}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		cluster.recordTransitions(watcher)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		watcher.Stop()
+		<-done
+	})
+	cluster.CreateResource(resource)
+	return cluster
+}
+
+// RunController runs the controller against the cluster until the test finishes. It returns when the controller
+// has listed DR resource and started watching it, so further changes are received as events.
+func (c *Cluster) RunController(ctr *controller.Controller) {
+	c.t.Helper()
+	watches := c.countWatches()
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- ctr.WithDynamicClient(c.DynamicClient).WithKubeClient(c.KubeClient).Run(ctx)
+	}()
+	c.t.Cleanup(func() {
+		cancel()
+		if err := <-done; err != nil {
+			c.t.Errorf("DR controller failed: %v", err)
+		}
+	})
+	deadline := time.Now().Add(startTimeout)
+	for c.countWatches() == watches {
+		if time.Now().After(deadline) {
+			c.t.Fatalf("DR controller has not started watching DR resource within %v", startTimeout)
+		}
+		time.Sleep(pollInterval)
+	}
+}
+
+func (c *Cluster) countWatches() int {
+	count := 0
+	for _, action := range c.DynamicClient.Actions() {
+		if action.GetVerb() == "watch" && action.GetResource() == c.gvr {
+			count++
+		}
+	}
+	return count
+}
+
+// SetMode requests the mode in the same way as Site Manager does with POST request to DRD server.
+func (c *Cluster) SetMode(mode string, noWait bool) (entity.SwitchoverState, error) {
+	return c.setModeUseCase().SetDrMode(context.Background(), entity.RequestData{Mode: mode, NoWait: &noWait})
+}
+
+// DryRun requests dry-run switchover in the same way as Site Manager does with POST request to DRD server.
+func (c *Cluster) DryRun(mode string, noWait bool) (entity.DryRunResult, error) {
+	return c.setModeUseCase().DryRun(context.Background(), entity.RequestData{Mode: mode, NoWait: &noWait, DryRun: true})
+}
+
+// Cancel requests cancellation of the current switchover in the same way as Site Manager does with DELETE request
+// to DRD server.
+func (c *Cluster) Cancel(caller string) (entity.SwitchoverState, error) {
+	return c.setModeUseCase().Cancel(caller)
+}
+
+// Operation returns switchover operation in the same way as Site Manager does with GET request to operations
+// endpoint of DRD server.
+func (c *Cluster) Operation(id string) (entity.Operation, error) {
+	return usecase.NewReadModeUseCase(c.crRepo, c.Config.DisasterRecoveryPath).GetOperation(id)
+}
+
+func (c *Cluster) setModeUseCase() *usecase.SetModeUseCase {
+	return usecase.NewSetModeUseCase(c.ctx, c.crRepo, c.Config.DisasterRecoveryPath, c.eventRecorder, c.Config.SwitchoverWaitTimeout,
+		c.Config.DryRunTimeout)
+}
+
+// Resource returns the current state of DR resource.
+func (c *Cluster) Resource() *unstructured.Unstructured {
+	c.t.Helper()
+	resource, err := c.DynamicClient.Resource(c.gvr).Namespace(c.Config.Namespace).
+		Get(context.Background(), c.Config.Name, metav1.GetOptions{})
+	if err != nil {
+		c.t.Fatalf("Cannot get DR resource: %v", err)
+	}
+	return resource
+}
+
+// UpdateResource changes DR resource, so the controller receives modification event.
+func (c *Cluster) UpdateResource(update func(resource *unstructured.Unstructured)) {
+	c.t.Helper()
+	resource := c.Resource()
+	update(resource)
+	_, err := c.DynamicClient.Resource(c.gvr).Namespace(c.Config.Namespace).
+		Update(context.Background(), resource, metav1.UpdateOptions{})
+	if err != nil {
+		c.t.Fatalf("Cannot update DR resource: %v", err)
+	}
+}
+
+// CreateResource creates DR resource, so the controller receives addition event.
+func (c *Cluster) CreateResource(resource *unstructured.Unstructured) {
+	c.t.Helper()
+	_, err := c.DynamicClient.Resource(c.gvr).Namespace(c.Config.Namespace).
+		Create(context.Background(), resource, metav1.CreateOptions{})
+	if err != nil {
+		c.t.Fatalf("Cannot create DR resource: %v", err)
+	}
+}
+
+// DeleteResource deletes DR resource, so the controller receives deletion event.
+func (c *Cluster) DeleteResource() {
+	c.t.Helper()
+	err := c.DynamicClient.Resource(c.gvr).Namespace(c.Config.Namespace).
+		Delete(context.Background(), c.Config.Name, metav1.DeleteOptions{})
+	if err != nil {
+		c.t.Fatalf("Cannot delete DR resource: %v", err)
+	}
+}
+
+// Status returns the current switchover state of DR resource.
+func (c *Cluster) Status() entity.SwitchoverState {
+	c.t.Helper()
+	state, err := c.crRepo.GetDrStatus(c.Config.StatusPath)
+	if err != nil {
+		c.t.Fatalf("Cannot get DR resource status: %v", err)
+	}
+	return state
+}
+
+// Transitions returns switchover states which DR resource had after the cluster creation, consecutive
+// equal states are recorded once.
+func (c *Cluster) Transitions() []entity.SwitchoverState {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return append([]entity.SwitchoverState(nil), c.transitions...)
+}
+
+// EventReasons returns reasons of Kubernetes Events which were recorded for DR resource in the order of their creation.
+// Events are sent asynchronously, so WaitForEvent should be called for the last expected event first.
+func (c *Cluster) EventReasons() []string {
+	c.t.Helper()
+	events, err := c.KubeClient.CoreV1().Events(c.Config.Namespace).List(context.Background(), metav1.ListOptions{})
+	if err != nil {
+		c.t.Fatalf("Cannot list events: %v", err)
+	}
+	var reasons []string
+	for _, event := range events.Items {
+		reasons = append(reasons, event.Reason)
+	}
+	return reasons
+}
+
+// WaitForEvent waits until Kubernetes Event with the given reason is recorded and fails the test on timeout.
+func (c *Cluster) WaitForEvent(reason string, timeout time.Duration) {
+	c.t.Helper()
+	deadline := time.Now().Add(timeout)
+	for !slices.Contains(c.EventReasons(), reason) {
+		if time.Now().After(deadline) {
+			c.t.Fatalf("Event '%s' has not been recorded within %v", reason, timeout)
+		}
+		time.Sleep(pollInterval)
+	}
+}
+
+// WaitForStatus waits until DR resource has the given mode and switchover status and fails the test on timeout.
+// It also waits until the state is recorded in transitions, which are watched asynchronously, so
+// AssertStatusTransitions can be called right after it.
+func (c *Cluster) WaitForStatus(mode string, status string, timeout time.Duration) entity.SwitchoverState {
+	c.t.Helper()
+	deadline := time.Now().Add(timeout)
+	for {
+		state := c.Status()
+		if state.Mode == mode && state.Status == status && c.lastTransitionIs(mode, status) {
+			return state
+		}
+		if time.Now().After(deadline) {
+			c.t.Fatalf("DR resource has not reached mode '%s' with status '%s' within %v, the last state is %+v",
+				mode, status, timeout, state)
+		}
+		time.Sleep(pollInterval)
+	}
+}
+
+// AssertStatusTransitions checks that switchover status went through exactly the given sequence of statuses.
+func (c *Cluster) AssertStatusTransitions(statuses ...string) bool {
+	c.t.Helper()
+	var actual []string
+	for _, state := range c.Transitions() {
+		if len(actual) == 0 || actual[len(actual)-1] != state.Status {
+			actual = append(actual, state.Status)
+		}
+	}
+	if !reflect.DeepEqual(actual, statuses) {
+		c.t.Errorf("Unexpected switchover status transitions: expected %v, actual %v", statuses, actual)
+		return false
+	}
+	return true
+}
+
+func (c *Cluster) lastTransitionIs(mode string, status string) bool {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if len(c.transitions) == 0 {
+		return false
+	}
+	last := c.transitions[len(c.transitions)-1]
+	return last.Mode == mode && last.Status == status
+}
+
+func (c *Cluster) recordTransitions(watcher watch.Interface) {
+	for event := range watcher.ResultChan() {
+		resource, ok := event.Object.(*unstructured.Unstructured)
+		if !ok || event.Type == watch.Deleted {
+			continue
+		}
+		state := c.switchoverState(resource)
+		c.mutex.Lock()
+		if len(c.transitions) == 0 || c.transitions[len(c.transitions)-1] != state {
+			c.transitions = append(c.transitions, state)
+		}
+		c.mutex.Unlock()
+	}
+}
+
+func (c *Cluster) switchoverState(resource *unstructured.Unstructured) entity.SwitchoverState {
+	statusPath := c.Config.StatusPath
+	var state entity.SwitchoverState
+	state.Mode, _, _ = unstructured.NestedString(resource.Object, statusPath.ModePath...)
+	state.Status, _, _ = unstructured.NestedString(resource.Object, statusPath.StatusPath...)
+	if len(statusPath.CommentPath) > 0 {
+		state.Comment, _, _ = unstructured.NestedString(resource.Object, statusPath.CommentPath...)
+	}
+	return state
+}
+
+type objectAction interface {
+	GetObject() runtime.Object
+}
+
+func (c *Cluster) setResourceVersion(action k8stesting.Action) (bool, runtime.Object, error) {
+	if action, ok := action.(objectAction); ok {
+		if object, ok := action.GetObject().(metav1.Object); ok {
+			object.SetResourceVersion(strconv.FormatInt(c.resourceVersion.Add(1), 10))
+		}
+	}
+	return false, nil, nil
+}
+
+// setGeneration increases generation of DR resource when the requested mode is changed, as API server does
+// on spec changes, otherwise the stored generation is kept.
+func (c *Cluster) setGeneration(action k8stesting.Action) (bool, runtime.Object, error) {
+	update, ok := action.(k8stesting.UpdateAction)
+	if !ok || update.GetSubresource() != "" {
+		return false, nil, nil
+	}
+	resource, ok := update.GetObject().(*unstructured.Unstructured)
+	if !ok || resource.GetName() != c.Config.Name {
+		return false, nil, nil
+	}
+	stored, err := c.DynamicClient.Tracker().Get(c.gvr, resource.GetNamespace(), resource.GetName())
+	if err != nil {
+		return false, nil, nil
+	}
+	storedResource, ok := stored.(*unstructured.Unstructured)
+	if !ok {
+		return false, nil, nil
+	}
+	storedMode, _, _ := unstructured.NestedString(storedResource.Object, c.Config.ModePath...)
+	mode, _, _ := unstructured.NestedString(resource.Object, c.Config.ModePath...)
+	resource.SetGeneration(storedResource.GetGeneration())
+	if mode != storedMode {
+		resource.SetGeneration(storedResource.GetGeneration() + 1)
+	}
+	return false, nil, nil
+}
+
+func (c *Cluster) generateName(action k8stesting.Action) (bool, runtime.Object, error) {
+	if action, ok := action.(objectAction); ok {
+		if object, ok := action.GetObject().(metav1.Object); ok && object.GetName() == "" && object.GetGenerateName() != "" {
+			// suffix is padded, so listed objects are sorted by creation order
+			object.SetName(fmt.Sprintf("%s%010d", object.GetGenerateName(), c.resourceVersion.Add(1)))
+		}
+	}
+	return false, nil, nil
+}
@@ -0,0 +1,83 @@
+// Copyright 2024-2025 NetCracker Technology Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package drdtest
+
+import (
+	"strconv"
+
+	"github.com/Netcracker/qubership-disaster-recovery-daemon/api/entity"
+	"github.com/Netcracker/qubership-disaster-recovery-daemon/config"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// EnvProvider provides environment variables from the map instead of the process environment.
+type EnvProvider map[string]string
+
+func (ep EnvProvider) GetEnv(key, fallback string) string {
+	if value, ok := ep[key]; ok {
+		return value
+	}
+	return fallback
+}
+
+// DefaultEnvs returns environment variables for DR resource which is a config map keeping mode and status in its data.
+// The result can be modified before it is passed to NewConfig.
+func DefaultEnvs() map[string]string {
+	return map[string]string{
+		"DISASTER_RECOVERY_MODE_PATH":           "data.mode",
+		"DISASTER_RECOVERY_NOWAIT_AS_STRING":    "true",
+		"DISASTER_RECOVERY_NOWAIT_PATH":         "data.noWait",
+		"DISASTER_RECOVERY_STATUS_COMMENT_PATH": "data.status_comment",
+		"DISASTER_RECOVERY_STATUS_MODE_PATH":    "data.status_mode",
+		"DISASTER_RECOVERY_STATUS_STATUS_PATH":  "data.status_status",
+		"HEALTH_MAIN_SERVICES_ACTIVE":           "statefulset test",
+		"IN_CLUSTER_CONFIG":                     "false",
+		"NAMESPACE":                             "test",
+		"RESOURCE_FOR_DR":                       `"" v1 configmaps dr-config`,
+		"TREAT_STATUS_AS_FIELD":                 "true",
+		"USE_DEFAULT_PATHS":                     "false",
+	}
+}
+
+// NewConfig builds DRD configuration from the given environment variables.
+func NewConfig(envs map[string]string) (*config.Config, error) {
+	return config.NewConfig(config.NewEnvConfigLoader(EnvProvider(envs)))
+}
+
+// NewResource builds DR resource of the given kind which is described by the configuration.
+// Both requested and current mode are set to the given mode and the switchover status is done.
+func NewResource(cfg *config.Config, kind string, mode string) *unstructured.Unstructured {
+	resource := &unstructured.Unstructured{Object: map[string]interface{}{}}
+	apiVersion := cfg.Version
+	if cfg.Group != "" {
+		apiVersion = cfg.Group + "/" + cfg.Version
+	}
+	resource.SetAPIVersion(apiVersion)
+	resource.SetKind(kind)
+	resource.SetName(cfg.Name)
+	resource.SetNamespace(cfg.Namespace)
+	_ = unstructured.SetNestedField(resource.Object, mode, cfg.ModePath...)
+	if cfg.NoWaitAsString {
+		_ = unstructured.SetNestedField(resource.Object, strconv.FormatBool(false), cfg.NoWaitPath...)
+	} else {
+		_ = unstructured.SetNestedField(resource.Object, false, cfg.NoWaitPath...)
+	}
+	_ = unstructured.SetNestedField(resource.Object, mode, cfg.StatusPath.ModePath...)
+	_ = unstructured.SetNestedField(resource.Object, entity.DONE, cfg.StatusPath.StatusPath...)
+	if len(cfg.StatusPath.CommentPath) > 0 {
+		_ = unstructured.SetNestedField(resource.Object, "", cfg.StatusPath.CommentPath...)
+	}
+	return resource
+}
@@ -0,0 +1,97 @@
+// Copyright 2024-2025 NetCracker Technology Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package drdtest
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Netcracker/qubership-disaster-recovery-daemon/api/entity"
+	"github.com/Netcracker/qubership-disaster-recovery-daemon/config"
+	"github.com/Netcracker/qubership-disaster-recovery-daemon/controller"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+const defaultScenarioTimeout = 30 * time.Second
+
+// ControllerFunc is a DR function which is set to the controller by WithFunc.
+type ControllerFunc func(request entity.ControllerRequest) (entity.ControllerResponse, error)
+
+// Scenario describes switchover which is requested via Site Manager API and processed by DR controller
+// with the given DR function.
+type Scenario struct {
+	Name string
+	// Resource is DR resource before switchover
+	Resource *unstructured.Unstructured
+	// Mode is requested by Site Manager
+	Mode   string
+	NoWait bool
+	Func   ControllerFunc
+	// Configure sets additional options of the controller, e.g. retry policy
+	Configure func(ctr *controller.Controller) *controller.Controller
+	// ExpectedStatus is a final status of switchover
+	ExpectedStatus string
+	// ExpectedTransitions are statuses which DR resource must go through, it is not checked if empty
+	ExpectedTransitions []string
+	// Timeout limits waiting for the final status, it is 30 seconds by default
+	Timeout time.Duration
+}
+
+// RunScenario runs the scenario as a subtest and returns the cluster for additional assertions.
+func RunScenario(t *testing.T, cfg *config.Config, scenario Scenario) *Cluster {
+	t.Helper()
+	var cluster *Cluster
+	t.Run(scenario.Name, func(t *testing.T) {
+		cluster = NewCluster(t, cfg, scenario.Resource)
+		ctr := controller.NewController(cfg).WithFunc(scenario.Func)
+		if scenario.Configure != nil {
+			ctr = scenario.Configure(ctr)
+		}
+		cluster.RunController(ctr)
+
+		state, err := cluster.SetMode(scenario.Mode, scenario.NoWait)
+		if err != nil {
+			t.Fatalf("Site Manager request failed: %v, state: %+v", err, state)
+		}
+		timeout := scenario.Timeout
+		if timeout <= 0 {
+			timeout = defaultScenarioTimeout
+		}
+		cluster.WaitForStatus(scenario.Mode, scenario.ExpectedStatus, timeout)
+		if len(scenario.ExpectedTransitions) > 0 {
+			cluster.AssertStatusTransitions(scenario.ExpectedTransitions...)
+		}
+	})
+	return cluster
+}
+
+// Succeed returns DR function which finishes switchover to the requested mode successfully.
+func Succeed() ControllerFunc {
+	return func(request entity.ControllerRequest) (entity.ControllerResponse, error) {
+		return entity.ControllerResponse{SwitchoverState: entity.SwitchoverState{Mode: request.Mode, Status: entity.DONE}}, nil
+	}
+}
+
+// FailTimes returns DR function which returns the error for the first n calls and then calls the given function.
+func FailTimes(n int, err error, then ControllerFunc) ControllerFunc {
+	var calls atomic.Int64
+	return func(request entity.ControllerRequest) (entity.ControllerResponse, error) {
+		if calls.Add(1) <= int64(n) {
+			return entity.ControllerResponse{}, err
+		}
+		return then(request)
+	}
+}
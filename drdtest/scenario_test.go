@@ -0,0 +1,276 @@
+// Copyright 2024-2025 NetCracker Technology Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package drdtest
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Netcracker/qubership-disaster-recovery-daemon/api/entity"
+	"github.com/Netcracker/qubership-disaster-recovery-daemon/controller"
+	"github.com/Netcracker/qubership-disaster-recovery-daemon/internal/usecase"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestRunScenario(t *testing.T) {
+	cfg, err := NewConfig(DefaultEnvs())
+	assert.NoErrorf(t, err, "Config should be built")
+
+	cluster := RunScenario(t, cfg, Scenario{
+		Name:     "active to standby, function fails twice, then succeeds",
+		Resource: NewResource(cfg, "ConfigMap", entity.ACTIVE),
+		Mode:     entity.STANDBY,
+		Func:     FailTimes(2, errors.New("replica is not ready"), Succeed()),
+		Configure: func(ctr *controller.Controller) *controller.Controller {
+			return ctr.WithRetry(3, 10*time.Millisecond)
+		},
+		ExpectedStatus:      entity.DONE,
+		ExpectedTransitions: []string{entity.DONE, entity.QUEUE, entity.RUNNING, entity.DONE},
+	})
+
+	cluster.WaitForEvent(entity.SwitchoverSucceededReason, 5*time.Second)
+	assert.Equalf(t, []string{entity.SwitchoverQueuedReason, entity.SwitchoverStartedReason, entity.SwitchoverRetriedReason,
+		entity.SwitchoverStartedReason, entity.SwitchoverRetriedReason, entity.SwitchoverStartedReason, entity.SwitchoverSucceededReason},
+		cluster.EventReasons(), "Events should be recorded for every transition")
+}
+
+func TestCluster_deleteAndRecreateResource(t *testing.T) {
+	cfg, err := NewConfig(DefaultEnvs())
+	assert.NoErrorf(t, err, "Config should be built")
+	deleted := make(chan string, 1)
+	cluster := NewCluster(t, cfg, NewResource(cfg, "ConfigMap", entity.STANDBY))
+	cluster.RunController(controller.NewController(cfg).WithFunc(Succeed()).WithDeleteFunc(func(request entity.ControllerRequest) {
+		deleted <- request.Mode
+	}))
+
+	cluster.DeleteResource()
+	select {
+	case mode := <-deleted:
+		assert.Equalf(t, entity.STANDBY, mode, "Delete function should receive the last known state")
+	case <-time.After(5 * time.Second):
+		t.Fatalf("Delete function has not been called")
+	}
+	resource := NewResource(cfg, "ConfigMap", entity.ACTIVE)
+	resource.Object["data"].(map[string]interface{})["status_status"] = ""
+	cluster.CreateResource(resource)
+
+	cluster.WaitForStatus(entity.ACTIVE, entity.DONE, 5*time.Second)
+}
+
+func TestCluster_dryRun(t *testing.T) {
+	envs := DefaultEnvs()
+	envs["DISASTER_RECOVERY_STATUS_DRY_RUN_PATH"] = "status.dryRun"
+	cfg, err := NewConfig(envs)
+	assert.NoErrorf(t, err, "Config should be built")
+	var requests []entity.ControllerRequest
+	cluster := NewCluster(t, cfg, NewResource(cfg, "ConfigMap", entity.ACTIVE))
+	cluster.RunController(controller.NewController(cfg).WithFunc(Succeed()).
+		WithDryRunFunc(func(request entity.ControllerRequest) (entity.DryRunResult, error) {
+			requests = append(requests, request)
+			return entity.DryRunResult{Verdict: entity.DryRunFailed, Findings: []string{"replica is not in sync"}}, nil
+		}))
+
+	result, err := cluster.DryRun(entity.STANDBY, false)
+
+	assert.NoErrorf(t, err, "Dry-run should finish")
+	assert.Equalf(t, entity.STANDBY, result.Mode, "Requested mode should be checked")
+	assert.Equalf(t, entity.DryRunFailed, result.Verdict, "Verdict of dry-run function should be returned")
+	assert.Equalf(t, []string{"replica is not in sync"}, result.Findings, "Findings of dry-run function should be returned")
+	assert.Lenf(t, requests, 1, "Dry-run function should be called once")
+	assert.Truef(t, requests[0].DryRun, "Request should be marked as dry-run")
+	assert.Equalf(t, entity.SwitchoverState{Mode: entity.ACTIVE, Status: entity.DONE}, cluster.Status(), "Status shouldn't change")
+	mode, _, _ := unstructured.NestedString(cluster.Resource().Object, cfg.ModePath...)
+	assert.Equalf(t, entity.ACTIVE, mode, "Mode shouldn't change")
+}
+
+func TestCluster_dryRunDoesNotBlockSwitchover(t *testing.T) {
+	envs := DefaultEnvs()
+	envs["DISASTER_RECOVERY_STATUS_DRY_RUN_PATH"] = "status.dryRun"
+	envs["DRY_RUN_TIMEOUT"] = "2s"
+	cfg, err := NewConfig(envs)
+	assert.NoErrorf(t, err, "Config should be built")
+	started := make(chan struct{})
+	cluster := NewCluster(t, cfg, NewResource(cfg, "ConfigMap", entity.ACTIVE))
+	cluster.RunController(controller.NewController(cfg).WithFunc(Succeed()).
+		WithDryRunContextFunc(func(ctx context.Context, request entity.ControllerRequest) (entity.DryRunResult, error) {
+			close(started)
+			<-ctx.Done()
+			return entity.DryRunResult{}, ctx.Err()
+		}))
+
+	go func() {
+		_, _ = cluster.DryRun(entity.STANDBY, false)
+	}()
+	select {
+	case <-started:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("Dry-run function has not been called")
+	}
+	_, err = cluster.SetMode(entity.STANDBY, true)
+	assert.NoErrorf(t, err, "Switchover should be requested")
+
+	cluster.WaitForStatus(entity.STANDBY, entity.DONE, time.Second)
+	assert.Eventuallyf(t, func() bool {
+		verdict, _, _ := unstructured.NestedString(cluster.Resource().Object, "status", "dryRun", "verdict")
+		return verdict == entity.DryRunFailed
+	}, 5*time.Second, 10*time.Millisecond, "Dry-run should fail when its deadline is exceeded")
+}
+
+func TestCluster_dryRunTimeout(t *testing.T) {
+	envs := DefaultEnvs()
+	envs["DISASTER_RECOVERY_STATUS_DRY_RUN_PATH"] = "status.dryRun"
+	envs["DRY_RUN_TIMEOUT"] = "200ms"
+	cfg, err := NewConfig(envs)
+	assert.NoErrorf(t, err, "Config should be built")
+	cluster := NewCluster(t, cfg, NewResource(cfg, "ConfigMap", entity.ACTIVE))
+
+	started := time.Now()
+	result, err := cluster.DryRun(entity.STANDBY, false)
+
+	assert.Errorf(t, err, "Dry-run should fail without DR controller")
+	assert.Lessf(t, time.Since(started), 5*time.Second, "Dry-run should be limited by the configured timeout")
+	assert.Equalf(t, entity.DryRunFailed, result.Verdict, "Verdict should be failed")
+	assert.Equalf(t, "DR controller has not reported dry-run verdict within 200ms", result.Comment, "Comment should contain timeout")
+}
+
+func TestCluster_cancelRunningSwitchover(t *testing.T) {
+	cfg, err := NewConfig(DefaultEnvs())
+	assert.NoErrorf(t, err, "Config should be built")
+	started := make(chan struct{})
+	cluster := NewCluster(t, cfg, NewResource(cfg, "ConfigMap", entity.ACTIVE))
+	cluster.RunController(controller.NewController(cfg).
+		WithContextFunc(func(ctx context.Context, request entity.ControllerRequest) (entity.ControllerResponse, error) {
+			close(started)
+			<-ctx.Done()
+			return entity.ControllerResponse{}, ctx.Err()
+		}))
+
+	_, err = cluster.Cancel("site-manager")
+	assert.ErrorIsf(t, err, entity.ErrNoSwitchoverInProgress, "Finished switchover shouldn't be cancelled")
+	_, err = cluster.SetMode(entity.STANDBY, true)
+	assert.NoErrorf(t, err, "Switchover should be requested")
+	select {
+	case <-started:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("DR function has not been called")
+	}
+	_, err = cluster.Cancel("site-manager")
+	assert.NoErrorf(t, err, "Running switchover should be cancelled")
+
+	state := cluster.WaitForStatus(entity.STANDBY, entity.FAILED, 5*time.Second)
+	assert.Equalf(t, "cancelled by site-manager", state.Comment, "Comment should contain the caller")
+	cluster.WaitForEvent(entity.SwitchoverCancelledReason, 5*time.Second)
+}
+
+func TestCluster_cancelQueuedSwitchoverOnStart(t *testing.T) {
+	cfg, err := NewConfig(DefaultEnvs())
+	assert.NoErrorf(t, err, "Config should be built")
+	resource := NewResource(cfg, "ConfigMap", entity.ACTIVE)
+	_ = unstructured.SetNestedField(resource.Object, entity.STANDBY, cfg.ModePath...)
+	_ = unstructured.SetNestedField(resource.Object, entity.QUEUE, cfg.StatusPath.StatusPath...)
+	resource.SetAnnotations(map[string]string{usecase.CancelAnnotationKey: `{"id":"1","caller":"site-manager"}`})
+	cluster := NewCluster(t, cfg, resource)
+	var calls atomic.Int32
+	cluster.RunController(controller.NewController(cfg).
+		WithFunc(func(request entity.ControllerRequest) (entity.ControllerResponse, error) {
+			calls.Add(1)
+			return entity.ControllerResponse{SwitchoverState: entity.SwitchoverState{Mode: request.Mode, Status: entity.DONE}}, nil
+		}))
+
+	state := cluster.WaitForStatus(entity.STANDBY, entity.FAILED, 5*time.Second)
+	assert.Equalf(t, "cancelled by site-manager", state.Comment, "Cancel request written before start should be applied")
+	assert.Equalf(t, int32(0), calls.Load(), "Function should not be called")
+}
+
+func TestCluster_operation(t *testing.T) {
+	envs := DefaultEnvs()
+	envs["DISASTER_RECOVERY_STATUS_OPERATIONS_PATH"] = "status.operations"
+	cfg, err := NewConfig(envs)
+	assert.NoErrorf(t, err, "Config should be built")
+	cluster := NewCluster(t, cfg, NewResource(cfg, "ConfigMap", entity.ACTIVE))
+	cluster.RunController(controller.NewController(cfg).WithFunc(Succeed()))
+
+	state, err := cluster.SetMode(entity.STANDBY, false)
+	assert.NoErrorf(t, err, "Switchover should be requested")
+	assert.NotEmptyf(t, state.OperationID, "Operation ID should be returned")
+	cluster.WaitForStatus(entity.STANDBY, entity.DONE, 5*time.Second)
+
+	operation, err := cluster.Operation(state.OperationID)
+	assert.NoErrorf(t, err, "Operation should be found")
+	assert.Equalf(t, entity.STANDBY, operation.Mode, "Operation should keep the requested mode")
+	assert.Equalf(t, entity.OperationSucceeded, operation.Phase, "Operation should succeed")
+	assert.NotEmptyf(t, operation.StartTime, "Start time should be set")
+	assert.NotEmptyf(t, operation.CompletionTime, "Completion time should be set")
+	_, err = cluster.Operation("unknown")
+	assert.ErrorIsf(t, err, entity.ErrOperationNotFound, "Unknown operation shouldn't be found")
+}
+
+func TestCluster_waitForSwitchover(t *testing.T) {
+	envs := DefaultEnvs()
+	envs["SWITCHOVER_WAIT_TIMEOUT"] = "500ms"
+	cfg, err := NewConfig(envs)
+	assert.NoErrorf(t, err, "Config should be built")
+	cluster := NewCluster(t, cfg, NewResource(cfg, "ConfigMap", entity.ACTIVE))
+
+	state, err := cluster.SetMode(entity.STANDBY, false)
+	assert.NoErrorf(t, err, "Switchover should be requested")
+	assert.Equalf(t, entity.QUEUE, state.Status, "Current status should be returned if switchover is not finished in time")
+
+	cluster.RunController(controller.NewController(cfg).WithFunc(Succeed()))
+	cluster.WaitForStatus(entity.STANDBY, entity.DONE, 5*time.Second)
+	state, err = cluster.SetMode(entity.ACTIVE, false)
+	assert.NoErrorf(t, err, "Switchover should be requested")
+	assert.Equalf(t, entity.SwitchoverState{Mode: entity.ACTIVE, Status: entity.DONE}, state,
+		"Final status should be returned if no-wait is false")
+}
+
+func TestCluster_observedGeneration(t *testing.T) {
+	envs := DefaultEnvs()
+	envs["DISASTER_RECOVERY_STATUS_OBSERVED_GENERATION_PATH"] = "status.observedGeneration"
+	envs["TREAT_STATUS_AS_FIELD"] = "false"
+	cfg, err := NewConfig(envs)
+	assert.NoErrorf(t, err, "Config should be built")
+	cluster := NewCluster(t, cfg, NewResource(cfg, "ConfigMap", entity.ACTIVE))
+	cluster.RunController(controller.NewController(cfg).WithFunc(Succeed()))
+
+	_, err = cluster.SetMode(entity.STANDBY, true)
+	assert.NoErrorf(t, err, "Switchover should be requested")
+	cluster.WaitForStatus(entity.STANDBY, entity.DONE, 5*time.Second)
+	resource := cluster.Resource()
+	observedGeneration, _, _ := unstructured.NestedInt64(resource.Object, cfg.StatusPath.ObservedGenerationPath...)
+	assert.Equalf(t, int64(1), resource.GetGeneration(), "Generation should be increased by mode change")
+	assert.Equalf(t, resource.GetGeneration(), observedGeneration, "Controller should report the generation as observed")
+}
+
+func TestCluster_skipModePickupForStatusAsField(t *testing.T) {
+	envs := DefaultEnvs()
+	envs["DISASTER_RECOVERY_STATUS_OBSERVED_GENERATION_PATH"] = "status.observedGeneration"
+	cfg, err := NewConfig(envs)
+	assert.NoErrorf(t, err, "Config should be built")
+	cluster := NewCluster(t, cfg, NewResource(cfg, "ConfigMap", entity.ACTIVE))
+	cluster.RunController(controller.NewController(cfg).WithFunc(Succeed()))
+
+	started := time.Now()
+	_, err = cluster.SetMode(entity.STANDBY, true)
+	assert.NoErrorf(t, err, "Switchover should be requested")
+	assert.Lessf(t, time.Since(started), 5*time.Second, "No-wait request shouldn't wait for mode pickup")
+	cluster.WaitForStatus(entity.STANDBY, entity.DONE, 5*time.Second)
+	_, found, _ := unstructured.NestedInt64(cluster.Resource().Object, cfg.StatusPath.ObservedGenerationPath...)
+	assert.Falsef(t, found, "Generation shouldn't be reported if status is treated as a field")
+}
@@ -15,36 +15,44 @@
 package app
 
 import (
+	"context"
 	"crypto/tls"
 	"crypto/x509"
 	"errors"
 	"fmt"
-	"github.com/Netcracker/qubership-disaster-recovery-daemon/client"
 	"github.com/Netcracker/qubership-disaster-recovery-daemon/config"
 	v1 "github.com/Netcracker/qubership-disaster-recovery-daemon/internal/controller/http/v1"
 	"github.com/Netcracker/qubership-disaster-recovery-daemon/internal/usecase"
 	"github.com/Netcracker/qubership-disaster-recovery-daemon/internal/usecase/repo"
+	"github.com/Netcracker/qubership-disaster-recovery-daemon/metrics"
 	"github.com/Netcracker/qubership-disaster-recovery-daemon/pkg/httpserver"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"log"
 	"net/http"
 	"os"
+	"time"
 )
 
-func Run(cfg *config.Config) {
+const healthyConditionInterval = 30 * time.Second
+
+func Run(ctx context.Context, cfg *config.Config, clientSet kubernetes.Interface, dynClient dynamic.Interface) {
 	serviceGVR := schema.GroupVersionResource{
 		Group:    cfg.Group,
 		Version:  cfg.Version,
 		Resource: cfg.Resource}
-	dynClient := client.MakeDynamicClient()
-	clientSet := client.MakeKubeClientSet()
 	httpClient := configureClient(fmt.Sprintf("%s/ca.crt", cfg.CertsPath))
 	kubernetesRepo := repo.NewKubernetesRepo(clientSet, cfg.Namespace)
 	crKubernetesRepo := repo.NewKubernetesCustomResourceRepo(dynClient, serviceGVR, cfg.Name, cfg.Namespace)
 	restClient := repo.NewRestClient(cfg.AdditionalHealthStatusConfig.Endpoint, httpClient)
 
 	healthUseCase := usecase.NewHealthUseCase(kubernetesRepo, crKubernetesRepo, cfg.HealthConfig, restClient)
+	go healthUseCase.MaintainHealthyCondition(ctx, healthyConditionInterval)
 	readStateUseCase := usecase.NewReadModeUseCase(crKubernetesRepo, cfg.DisasterRecoveryPath)
-	setModeUseCase := usecase.NewSetModeUseCase(crKubernetesRepo, cfg.DisasterRecoveryPath)
+	eventRecorder := repo.NewKubernetesEventRecorder(ctx, clientSet, "disaster-recovery-server")
+	setModeUseCase := usecase.NewSetModeUseCase(ctx, crKubernetesRepo, cfg.DisasterRecoveryPath, eventRecorder,
+		cfg.SwitchoverWaitTimeout, cfg.DryRunTimeout)
 
 	authenticator := v1.NewTokenReviewAuthenticator(clientSet, cfg.AuthConfig)
 
@@ -53,8 +61,27 @@ func Run(cfg *config.Config) {
 	serverHandler.NewHealthzRoute(healthUseCase)
 	serverHandler.NewReadModeRoute(readStateUseCase)
 	serverHandler.NewUpdateModeRoute(setModeUseCase)
+	serverHandler.NewCancelModeRoute(setModeUseCase)
+	serverHandler.NewReadOperationRoute(readStateUseCase)
+	if cfg.MetricsPort == cfg.Port {
+		serverHandler.NewMetricsRoute()
+	} else {
+		go startMetricsServer(ctx, cfg.MetricsPort)
+	}
 	httpHandler := serverHandler.BuildHandler()
-	_ = httpserver.StartServer(httpHandler, cfg.ServerConfig)
+	if err := httpserver.StartServerWithContext(ctx, httpHandler, cfg.ServerConfig); err != nil {
+		log.Printf("DR server is stopped: %v", err)
+	}
+}
+
+// startMetricsServer exposes metrics over plain HTTP on a separate port, so monitoring does not need
+// DRD server certificates.
+func startMetricsServer(ctx context.Context, port int) {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", metrics.Handler())
+	if err := httpserver.StartServerWithContext(ctx, mux, config.ServerConfig{Port: port}); err != nil {
+		log.Printf("Metrics server is stopped: %v", err)
+	}
 }
 
 func configureClient(certificateFilePath string) http.Client {
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"github.com/Netcracker/qubership-disaster-recovery-daemon/config"
+	"github.com/Netcracker/qubership-disaster-recovery-daemon/metrics"
 	v1 "k8s.io/api/authentication/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
@@ -16,7 +17,14 @@ type Authenticator interface {
 	CheckAuth(r *http.Request) (bool, string)
 }
 
-func NewTokenReviewAuthenticator(clientSet *kubernetes.Clientset, cfg config.AuthConfig) *TokenReviewAuthenticator {
+// IdentityAuthenticator is implemented by authenticators which also return the name of authenticated user
+// if it is known, in addition to the result and the token from the request.
+type IdentityAuthenticator interface {
+	Authenticator
+	CheckIdentity(r *http.Request) (bool, string, string)
+}
+
+func NewTokenReviewAuthenticator(clientSet kubernetes.Interface, cfg config.AuthConfig) *TokenReviewAuthenticator {
 	return &TokenReviewAuthenticator{
 		clientSet: clientSet,
 		config:    cfg,
@@ -24,17 +32,23 @@ func NewTokenReviewAuthenticator(clientSet *kubernetes.Clientset, cfg config.Aut
 }
 
 type TokenReviewAuthenticator struct {
-	clientSet *kubernetes.Clientset
+	clientSet kubernetes.Interface
 	config    config.AuthConfig
 }
 
 func (tra TokenReviewAuthenticator) CheckAuth(r *http.Request) (bool, string) {
+	authenticated, token, _ := tra.CheckIdentity(r)
+	return authenticated, token
+}
+
+func (tra TokenReviewAuthenticator) CheckIdentity(r *http.Request) (bool, string, string) {
 	if !tra.config.AuthEnabled {
-		return true, ""
+		return true, "", ""
 	}
 	token := tra.extractToken(r)
 	if token == "" {
-		return false, ""
+		metrics.ObserveAuthenticationFailure(metrics.MissingTokenReason)
+		return false, "", ""
 	}
 	reviewer := tra.clientSet.AuthenticationV1().TokenReviews()
 	tokenReview := v1.TokenReview{}
@@ -45,15 +59,17 @@ func (tra TokenReviewAuthenticator) CheckAuth(r *http.Request) (bool, string) {
 	reviewResult, err := reviewer.Create(context.TODO(), &tokenReview, metav1.CreateOptions{})
 	if err != nil {
 		log.Println("Can not create Kubernetes token reviewer")
-		return false, token
+		metrics.ObserveAuthenticationFailure(metrics.TokenReviewErrorReason)
+		return false, token, ""
 	} else {
 		authenticated := reviewResult.Status.Authenticated
 		if authenticated {
 			authenticated = tra.checkNamespaceAndSa(reviewResult)
 		} else {
 			log.Println("Unauthorized access")
+			metrics.ObserveAuthenticationFailure(metrics.UnauthenticatedReason)
 		}
-		return authenticated, token
+		return authenticated, token, reviewResult.Status.User.Username
 	}
 }
 
@@ -72,6 +88,7 @@ func (tra TokenReviewAuthenticator) checkNamespaceAndSa(reviewResult *v1.TokenRe
 		tra.config.SiteManagerNamespace, tra.config.SiteManagerServiceAccountName)
 	if !authenticated {
 		log.Println("service account name or namespace of given token is not allowed")
+		metrics.ObserveAuthenticationFailure(metrics.ForbiddenAccountReason)
 	}
 	return authenticated
 }
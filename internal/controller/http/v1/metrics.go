@@ -0,0 +1,39 @@
+package v1
+
+import (
+	"github.com/Netcracker/qubership-disaster-recovery-daemon/metrics"
+	"github.com/gorilla/mux"
+	"net/http"
+	"time"
+)
+
+// NewMetricsRoute exposes Prometheus metrics without authentication, so they can be scraped by monitoring.
+func (sh *ServerHandler) NewMetricsRoute() {
+	sh.router.Handle("/metrics", metrics.Handler()).Methods(http.MethodGet)
+}
+
+// metricsMiddleware records every request to the matched route with its response code and duration.
+func metricsMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		started := time.Now()
+		recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(recorder, r)
+		route := r.URL.Path
+		if current := mux.CurrentRoute(r); current != nil {
+			if template, err := current.GetPathTemplate(); err == nil {
+				route = template
+			}
+		}
+		metrics.ObserveHTTPRequest(route, r.Method, recorder.status, time.Since(started))
+	})
+}
+
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (sr *statusRecorder) WriteHeader(status int) {
+	sr.status = status
+	sr.ResponseWriter.WriteHeader(status)
+}
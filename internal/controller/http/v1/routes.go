@@ -1,7 +1,9 @@
 package v1
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"github.com/Netcracker/qubership-disaster-recovery-daemon/api/entity"
 	"github.com/Netcracker/qubership-disaster-recovery-daemon/internal/usecase"
@@ -19,6 +21,7 @@ var (
 
 func NewServerHandler(authenticator Authenticator) *ServerHandler {
 	router := mux.NewRouter()
+	router.Use(metricsMiddleware)
 	return &ServerHandler{
 		router:        router,
 		authenticator: authenticator,
@@ -46,6 +49,14 @@ func (sh *ServerHandler) NewUpdateModeRoute(useCase usecase.SetMode) {
 	sh.router.Handle("/sitemanager", http.HandlerFunc(sh.authenticationWrapper(setMode(useCase)))).Methods(http.MethodPost)
 }
 
+func (sh *ServerHandler) NewCancelModeRoute(useCase usecase.SetMode) {
+	sh.router.Handle("/sitemanager", http.HandlerFunc(sh.authenticationWrapper(cancelMode(useCase)))).Methods(http.MethodDelete)
+}
+
+func (sh *ServerHandler) NewReadOperationRoute(useCase usecase.ReadMode) {
+	sh.router.Handle("/sitemanager/operations/{id}", http.HandlerFunc(sh.authenticationWrapper(getOperation(useCase)))).Methods(http.MethodGet)
+}
+
 func (sh *ServerHandler) BuildHandler() http.Handler {
 	return JsonContentType(handlers.CompressHandler(sh.router))
 }
@@ -60,7 +71,8 @@ func JsonContentType(h http.Handler) http.Handler {
 func (sh *ServerHandler) authenticationWrapper(f func(w http.ResponseWriter, r *http.Request)) func(w http.ResponseWriter,
 	r *http.Request) {
 	return func(w http.ResponseWriter, r *http.Request) {
-		if authenticated, token := sh.authenticator.CheckAuth(r); !authenticated {
+		authenticated, token, user := sh.checkAuth(r)
+		if !authenticated {
 			log.Println("Unauthorized request.")
 			if token == "" {
 				w.Header().Set("WWW-Authenticate", "Bearer")
@@ -72,14 +84,38 @@ func (sh *ServerHandler) authenticationWrapper(f func(w http.ResponseWriter, r *
 			}
 			return
 		}
+		if user != "" {
+			r = r.WithContext(context.WithValue(r.Context(), userContextKey{}, user))
+		}
 		f(w, r)
 	}
 }
 
+// checkAuth authenticates the request, the name of authenticated user is known only for IdentityAuthenticator.
+func (sh *ServerHandler) checkAuth(r *http.Request) (bool, string, string) {
+	if identityAuthenticator, ok := sh.authenticator.(IdentityAuthenticator); ok {
+		return identityAuthenticator.CheckIdentity(r)
+	}
+	authenticated, token := sh.authenticator.CheckAuth(r)
+	return authenticated, token, ""
+}
+
+type userContextKey struct{}
+
+// getCaller returns the name of authenticated user or the client address if authentication is disabled.
+func getCaller(r *http.Request) string {
+	if user, ok := r.Context().Value(userContextKey{}).(string); ok {
+		return user
+	}
+	return r.RemoteAddr
+}
+
 func getHealth(useCase usecase.ReadMode) func(w http.ResponseWriter, r *http.Request) {
 	return func(w http.ResponseWriter, r *http.Request) {
 		state, err := useCase.GetModeAndStatus()
-		if err != nil {
+		if errors.Is(err, entity.ErrResourceNotFound) {
+			sendFailedSwitchoverResponse(w, http.StatusNotFound, "", err.Error())
+		} else if err != nil {
 			sendResponse(w, http.StatusInternalServerError, err)
 		} else {
 			sendSuccessfulResponse(w, state)
@@ -93,7 +129,7 @@ func getClusterHealthStatus(useCase usecase.Health) func(w http.ResponseWriter,
 		healthState, err := useCase.GetHealth()
 		if err != nil {
 			log.Printf("Can not get the service health. Error is [%v]", err)
-			sendFailedHealthResponse(w)
+			sendFailedHealthResponse(w, failedStatusCode(err), err)
 			return
 		}
 		log.Printf("The disaster recovery health state is [%v]", healthState)
@@ -108,7 +144,7 @@ func getModeAndStatus(useCase usecase.ReadMode) func(w http.ResponseWriter, r *h
 		if err != nil {
 			comment := fmt.Sprintf("Can not get a disaster recovery state. Error is [%v]", err)
 			log.Println(comment)
-			sendFailedSwitchoverResponse(w, "", comment)
+			sendFailedSwitchoverResponse(w, failedStatusCode(err), "", comment)
 			return
 		}
 		log.Printf("The disaster recovery status is [%v]", state)
@@ -123,46 +159,111 @@ func setMode(useCase usecase.SetMode) func(w http.ResponseWriter, r *http.Reques
 		if err != nil {
 			comment := fmt.Sprintf("Reading data from request failed. Error is [%v]", err)
 			log.Println(comment)
-			sendFailedSwitchoverResponse(w, "", comment)
+			sendFailedSwitchoverResponse(w, http.StatusInternalServerError, "", comment)
 			return
 		}
 		err = json.Unmarshal(requestBody, &data)
 		if err != nil {
 			comment := fmt.Sprintf("Unmarshalling data from request failed. Error is [%v]", err)
 			log.Println(comment)
-			sendFailedSwitchoverResponse(w, "", comment)
+			sendFailedSwitchoverResponse(w, http.StatusInternalServerError, "", comment)
 			return
 		}
 		log.Printf("New request for disaster recovery mode changing has been received. The request body is [%v]", data)
+		if data.DryRun {
+			dryRun(r.Context(), w, useCase, data)
+			return
+		}
 
-		switchoverState, err := useCase.SetDrMode(data)
+		switchoverState, err := useCase.SetDrMode(r.Context(), data)
 		if err != nil {
 			log.Printf("can not set disaster recovery mode. Error is [%v]", err)
-			sendFailedSwitchoverResponse(w, switchoverState.Mode, switchoverState.Comment)
+			sendFailedSwitchoverResponse(w, failedStatusCode(err), switchoverState.Mode, switchoverState.Comment)
+			return
+		}
+		// unfinished switchover is tracked as operation, so the caller is pointed to the operation
+		if switchoverState.OperationID != "" && switchoverState.Status != entity.DONE && switchoverState.Status != entity.FAILED {
+			w.Header().Set("Location", "/sitemanager/operations/"+switchoverState.OperationID)
+			sendResponse(w, http.StatusAccepted, switchoverState)
 			return
 		}
 		sendSuccessfulResponse(w, switchoverState)
 	}
 }
 
+func getOperation(useCase usecase.ReadMode) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id := mux.Vars(r)["id"]
+		operation, err := useCase.GetOperation(id)
+		if err != nil {
+			comment := fmt.Sprintf("Can not get switchover operation. Error is [%v]", err)
+			log.Println(comment)
+			sendFailedSwitchoverResponse(w, failedStatusCode(err), "", comment)
+			return
+		}
+		sendSuccessfulResponse(w, operation)
+	}
+}
+
+func cancelMode(useCase usecase.SetMode) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		caller := getCaller(r)
+		log.Printf("New request for switchover cancellation has been received from %s", caller)
+		switchoverState, err := useCase.Cancel(caller)
+		if err != nil {
+			log.Printf("can not cancel switchover. Error is [%v]", err)
+			sendResponse(w, failedStatusCode(err), switchoverState)
+			return
+		}
+		sendSuccessfulResponse(w, switchoverState)
+	}
+}
+
+func dryRun(ctx context.Context, w http.ResponseWriter, useCase usecase.SetMode, data entity.RequestData) {
+	result, err := useCase.DryRun(ctx, data)
+	if err != nil {
+		log.Printf("can not perform dry-run of disaster recovery mode changing. Error is [%v]", err)
+		sendResponse(w, failedStatusCode(err), result)
+		return
+	}
+	log.Printf("Dry-run verdict for disaster recovery mode changing is [%v]", result)
+	sendSuccessfulResponse(w, result)
+}
+
 func sendSuccessfulResponse(w http.ResponseWriter, response interface{}) {
 	sendResponse(w, http.StatusOK, response)
 }
 
-func sendFailedHealthResponse(w http.ResponseWriter) {
+func sendFailedHealthResponse(w http.ResponseWriter, statusCode int, err error) {
 	response := entity.HealthResponse{
 		Status: entity.DOWN,
 	}
-	sendResponse(w, http.StatusInternalServerError, response)
+	// missing DR resource is reported explicitly, other errors are not exposed by health endpoint
+	if errors.Is(err, entity.ErrResourceNotFound) {
+		response.Comment = err.Error()
+	}
+	sendResponse(w, statusCode, response)
 }
 
-func sendFailedSwitchoverResponse(w http.ResponseWriter, mode string, comment string) {
+func sendFailedSwitchoverResponse(w http.ResponseWriter, statusCode int, mode string, comment string) {
 	response := entity.SwitchoverState{
 		Mode:    mode,
 		Status:  entity.FAILED,
 		Comment: comment,
 	}
-	sendResponse(w, http.StatusInternalServerError, response)
+	sendResponse(w, statusCode, response)
+}
+
+// failedStatusCode returns 404 if DR resource or operation does not exist and 409 if there is no switchover to cancel,
+// so they are not confused with internal errors.
+func failedStatusCode(err error) int {
+	if errors.Is(err, entity.ErrResourceNotFound) || errors.Is(err, entity.ErrOperationNotFound) {
+		return http.StatusNotFound
+	}
+	if errors.Is(err, entity.ErrNoSwitchoverInProgress) {
+		return http.StatusConflict
+	}
+	return http.StatusInternalServerError
 }
 
 func sendResponse(w http.ResponseWriter, statusCode int, response interface{}) {
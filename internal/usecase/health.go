@@ -15,13 +15,17 @@
 package usecase
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"github.com/Netcracker/qubership-disaster-recovery-daemon/api/entity"
 	"github.com/Netcracker/qubership-disaster-recovery-daemon/config"
+	"github.com/Netcracker/qubership-disaster-recovery-daemon/metrics"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"log"
 	"net/http"
 	"strings"
+	"time"
 )
 
 func NewHealthUseCase(kr KubernetesRepo,
@@ -43,12 +47,66 @@ type HealthUseCase struct {
 	restClient RestClient
 }
 
+// GetHealth checks the health of services in the current DR mode, it does not change DR resource.
 func (hus HealthUseCase) GetHealth() (entity.HealthResponse, error) {
+	mode, healthResponse, err := hus.checkHealth()
+	if err != nil {
+		return healthResponse, err
+	}
+	metrics.ObserveHealth(mode, healthResponse.Status)
+	return healthResponse, nil
+}
+
+// MaintainHealthyCondition checks the health periodically until the context is done and writes Healthy condition
+// to DR resource status only when its status or reason changes, so health probes never write to API server.
+// It does nothing if conditions path is not specified.
+func (hus HealthUseCase) MaintainHealthyCondition(ctx context.Context, interval time.Duration) {
+	if len(hus.config.DisasterRecoveryStatusPath.ConditionsPath) == 0 {
+		return
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	var written *metav1.Condition
+	for {
+		written = hus.updateHealthyCondition(written)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// updateHealthyCondition writes Healthy condition if it differs from the written one and returns the condition
+// which is written to DR resource.
+func (hus HealthUseCase) updateHealthyCondition(written *metav1.Condition) *metav1.Condition {
+	_, healthResponse, err := hus.checkHealth()
+	if err != nil {
+		log.Printf("Can not check health to update healthy condition of disaster recovery resource. Error is [%v]", err)
+		return written
+	}
+	condition := healthyCondition(healthResponse)
+	if written != nil && written.Status == condition.Status && written.Reason == condition.Reason {
+		return written
+	}
+	if err := hus.crRepo.UpdateConditions(hus.config.DisasterRecoveryStatusPath, condition); err != nil {
+		log.Printf("Can not update healthy condition of disaster recovery resource. Error is [%v]", err)
+		return written
+	}
+	return &condition
+}
+
+func (hus HealthUseCase) checkHealth() (string, entity.HealthResponse, error) {
 	drStatus, err := hus.crRepo.GetDrStatus(hus.config.DisasterRecoveryStatusPath)
 	if err != nil {
-		return entity.HealthResponse{}, err
+		return "", entity.HealthResponse{}, err
 	}
 	mode := strings.ToLower(drStatus.Mode)
+	healthResponse, err := hus.getHealth(mode)
+	return mode, healthResponse, err
+}
+
+func (hus HealthUseCase) getHealth(mode string) (entity.HealthResponse, error) {
 	if hus.config.AdditionalHealthStatusConfig.FullHealthEnabled && hus.isCustomHealthNeeded() {
 		return hus.getCustomHealth(mode)
 	}
@@ -138,6 +196,20 @@ func getServiceState(mainServiceState string, additionalServiceState string, add
 	}
 }
 
+func healthyCondition(healthResponse entity.HealthResponse) metav1.Condition {
+	condition := metav1.Condition{Type: entity.HealthyCondition, Status: metav1.ConditionFalse}
+	switch strings.ToLower(healthResponse.Status) {
+	case entity.UP:
+		condition.Status = metav1.ConditionTrue
+		condition.Reason = "ServiceUp"
+	case entity.DEGRADED:
+		condition.Reason = "ServiceDegraded"
+	default:
+		condition.Reason = "ServiceDown"
+	}
+	return condition
+}
+
 func (hus HealthUseCase) isCustomHealthNeeded() bool {
 	return hus.config.AdditionalHealthStatusConfig.HealthFunc != nil || hus.config.AdditionalHealthStatusConfig.Endpoint != ""
 }
@@ -0,0 +1,85 @@
+// Copyright 2024-2025 NetCracker Technology Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package usecase
+
+import (
+	"testing"
+
+	"github.com/Netcracker/qubership-disaster-recovery-daemon/api/entity"
+	"github.com/Netcracker/qubership-disaster-recovery-daemon/config"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestHealthyCondition(t *testing.T) {
+	tests := []struct {
+		status         string
+		expectedStatus metav1.ConditionStatus
+		expectedReason string
+	}{
+		{status: entity.UP, expectedStatus: metav1.ConditionTrue, expectedReason: "ServiceUp"},
+		{status: "UP", expectedStatus: metav1.ConditionTrue, expectedReason: "ServiceUp"},
+		{status: entity.DEGRADED, expectedStatus: metav1.ConditionFalse, expectedReason: "ServiceDegraded"},
+		{status: entity.DOWN, expectedStatus: metav1.ConditionFalse, expectedReason: "ServiceDown"},
+		{status: "", expectedStatus: metav1.ConditionFalse, expectedReason: "ServiceDown"},
+	}
+	for _, test := range tests {
+		condition := healthyCondition(entity.HealthResponse{Status: test.status})
+		assert.Equalf(t, entity.HealthyCondition, condition.Type, "Condition type should be healthy")
+		assert.Equalf(t, test.expectedStatus, condition.Status, "Unexpected condition status for '%s' health", test.status)
+		assert.Equalf(t, test.expectedReason, condition.Reason, "Unexpected condition reason for '%s' health", test.status)
+	}
+}
+
+// testCustomResourceRepo implements only methods which are used by health use case
+type testCustomResourceRepo struct {
+	KubernetesCustomResourceRepo
+	mode       string
+	conditions []metav1.Condition
+}
+
+func (t *testCustomResourceRepo) GetDrStatus(config.DisasterRecoveryStatusPath) (entity.SwitchoverState, error) {
+	return entity.SwitchoverState{Mode: t.mode, Status: entity.DONE}, nil
+}
+
+func (t *testCustomResourceRepo) UpdateConditions(_ config.DisasterRecoveryStatusPath, conditions ...metav1.Condition) error {
+	t.conditions = append(t.conditions, conditions...)
+	return nil
+}
+
+func TestHealthUseCase_writeHealthyConditionOnChange(t *testing.T) {
+	status := entity.UP
+	crRepo := &testCustomResourceRepo{mode: entity.ACTIVE}
+	healthConfig := config.HealthConfig{}
+	healthConfig.DisasterRecoveryStatusPath.ConditionsPath = []string{"status", "conditions"}
+	healthConfig.AdditionalHealthStatusConfig.FullHealthEnabled = true
+	healthConfig.AdditionalHealthStatusConfig.HealthFunc = func(entity.HealthRequest) (entity.HealthResponse, error) {
+		return entity.HealthResponse{Status: status}, nil
+	}
+	hus := NewHealthUseCase(nil, crRepo, healthConfig, nil)
+
+	response, err := hus.GetHealth()
+	assert.NoErrorf(t, err, "Health should be checked")
+	assert.Equalf(t, entity.UP, response.Status, "Health should be returned")
+	assert.Emptyf(t, crRepo.conditions, "Health check shouldn't write conditions")
+
+	written := hus.updateHealthyCondition(nil)
+	written = hus.updateHealthyCondition(written)
+	assert.Lenf(t, crRepo.conditions, 1, "The same condition should be written once")
+	status = entity.DOWN
+	written = hus.updateHealthyCondition(written)
+	assert.Lenf(t, crRepo.conditions, 2, "Changed condition should be written")
+	assert.Equalf(t, "ServiceDown", written.Reason, "Written condition should be returned")
+}
@@ -15,9 +15,13 @@
 package usecase
 
 import (
+	"context"
 	"github.com/Netcracker/qubership-disaster-recovery-daemon/api/entity"
 	"github.com/Netcracker/qubership-disaster-recovery-daemon/config"
 	"io"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
 )
 
 type Health interface {
@@ -26,10 +30,13 @@ type Health interface {
 
 type ReadMode interface {
 	GetModeAndStatus() (entity.SwitchoverState, error)
+	GetOperation(id string) (entity.Operation, error)
 }
 
 type SetMode interface {
-	SetDrMode(entity.RequestData) (entity.SwitchoverState, error)
+	SetDrMode(context.Context, entity.RequestData) (entity.SwitchoverState, error)
+	DryRun(context.Context, entity.RequestData) (entity.DryRunResult, error)
+	Cancel(caller string) (entity.SwitchoverState, error)
 }
 
 type KubernetesRepo interface {
@@ -39,9 +46,23 @@ type KubernetesRepo interface {
 type KubernetesCustomResourceRepo interface {
 	GetDrMode(...string) (string, error)
 	GetDrStatus(path config.DisasterRecoveryStatusPath) (entity.SwitchoverState, error)
+	WaitForStatus(context.Context, config.DisasterRecoveryStatusPath, func(entity.SwitchoverState) bool) (entity.SwitchoverState, error)
+	WaitForObservedGeneration(context.Context, config.DisasterRecoveryStatusPath) error
+	WaitForField(context.Context, []string, func(interface{}) (bool, error)) error
+	GetResource() (*unstructured.Unstructured, error)
 	GetResourceVersion() (string, error)
+	GetField(...string) (interface{}, bool, error)
+	UpdateAnnotations(map[string]string) error
 	UpdateDrMode(config.DisasterRecoveryPath, entity.ModeDataUpdate) error
-	UpdateStatus(config.DisasterRecoveryStatusPath, entity.SwitchoverState) error
+	UpdateStatus(config.DisasterRecoveryStatusPath, entity.SwitchoverState, ...entity.StatusField) error
+	UpdateStatusFields(config.DisasterRecoveryStatusPath, ...entity.StatusField) error
+	UpdateConditions(config.DisasterRecoveryStatusPath, ...metav1.Condition) error
+	AddOperation(config.DisasterRecoveryStatusPath, entity.Operation) error
+	FailOperation(config.DisasterRecoveryStatusPath, string, string) error
+}
+
+type EventRecorder interface {
+	Event(object runtime.Object, eventType string, reason string, message string)
 }
 
 type RestClient interface {
@@ -15,8 +15,12 @@
 package usecase
 
 import (
+	"fmt"
+
 	"github.com/Netcracker/qubership-disaster-recovery-daemon/api/entity"
 	"github.com/Netcracker/qubership-disaster-recovery-daemon/config"
+	"github.com/Netcracker/qubership-disaster-recovery-daemon/metrics"
+	"k8s.io/apimachinery/pkg/runtime"
 )
 
 func NewReadModeUseCase(crr KubernetesCustomResourceRepo, config config.DisasterRecoveryPath) *ReadModeUseCase {
@@ -32,5 +36,33 @@ type ReadModeUseCase struct {
 }
 
 func (rmuc ReadModeUseCase) GetModeAndStatus() (entity.SwitchoverState, error) {
-	return rmuc.crRepo.GetDrStatus(rmuc.config.StatusPath)
+	state, err := rmuc.crRepo.GetDrStatus(rmuc.config.StatusPath)
+	if err == nil {
+		metrics.SetState(state)
+	}
+	return state, err
+}
+
+// GetOperation returns switchover operation with the given ID from DR resource status.
+func (rmuc ReadModeUseCase) GetOperation(id string) (entity.Operation, error) {
+	operationsPath := rmuc.config.StatusPath.OperationsPath
+	if len(operationsPath) == 0 {
+		return entity.Operation{}, fmt.Errorf("%w: operations are not kept, DISASTER_RECOVERY_STATUS_OPERATIONS_PATH is not specified",
+			entity.ErrOperationNotFound)
+	}
+	field, _, err := rmuc.crRepo.GetField(operationsPath...)
+	if err != nil {
+		return entity.Operation{}, err
+	}
+	items, _ := field.([]interface{})
+	for _, item := range items {
+		itemMap, ok := item.(map[string]interface{})
+		if !ok || itemMap["id"] != id {
+			continue
+		}
+		var operation entity.Operation
+		err = runtime.DefaultUnstructuredConverter.FromUnstructured(itemMap, &operation)
+		return operation, err
+	}
+	return entity.Operation{}, fmt.Errorf("%w: '%s'", entity.ErrOperationNotFound, id)
 }
@@ -0,0 +1,89 @@
+// Copyright 2024-2025 NetCracker Technology Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package repo
+
+import (
+	"github.com/Netcracker/qubership-disaster-recovery-daemon/api/entity"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// switchoverConditions converts switchover state to conditions, so tools which understand only conditions
+// can wait for switchover result.
+func switchoverConditions(state entity.SwitchoverState) []metav1.Condition {
+	switch state.Status {
+	case entity.QUEUE:
+		return []metav1.Condition{
+			{Type: entity.SwitchoverInProgressCondition, Status: metav1.ConditionTrue, Reason: "SwitchoverQueued", Message: state.Comment},
+		}
+	case entity.RUNNING:
+		return []metav1.Condition{
+			{Type: entity.SwitchoverInProgressCondition, Status: metav1.ConditionTrue, Reason: "SwitchoverRunning", Message: state.Comment},
+		}
+	case entity.DONE:
+		return []metav1.Condition{
+			{Type: entity.SwitchoverInProgressCondition, Status: metav1.ConditionFalse, Reason: "SwitchoverFinished"},
+			{Type: entity.SwitchoverSucceededCondition, Status: metav1.ConditionTrue, Reason: "SwitchoverSucceeded", Message: state.Comment},
+		}
+	case entity.FAILED:
+		return []metav1.Condition{
+			{Type: entity.SwitchoverInProgressCondition, Status: metav1.ConditionFalse, Reason: "SwitchoverFinished"},
+			{Type: entity.SwitchoverSucceededCondition, Status: metav1.ConditionFalse, Reason: "SwitchoverFailed", Message: state.Comment},
+		}
+	default:
+		return nil
+	}
+}
+
+// setConditions merges conditions into the list by the path, observedGeneration is taken from the resource.
+func setConditions(cr *unstructured.Unstructured, path []string, newConditions []metav1.Condition) (bool, error) {
+	items, _, err := unstructured.NestedSlice(cr.Object, path...)
+	if err != nil {
+		return false, err
+	}
+	conditions := make([]metav1.Condition, 0, len(items))
+	for _, item := range items {
+		itemMap, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		var condition metav1.Condition
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(itemMap, &condition); err != nil {
+			return false, err
+		}
+		conditions = append(conditions, condition)
+	}
+	changed := false
+	for _, condition := range newConditions {
+		condition.ObservedGeneration = cr.GetGeneration()
+		if meta.SetStatusCondition(&conditions, condition) {
+			changed = true
+		}
+	}
+	if !changed {
+		return false, nil
+	}
+	result := make([]interface{}, 0, len(conditions))
+	for i := range conditions {
+		item, err := runtime.DefaultUnstructuredConverter.ToUnstructured(&conditions[i])
+		if err != nil {
+			return false, err
+		}
+		result = append(result, item)
+	}
+	return true, unstructured.SetNestedSlice(cr.Object, result, path...)
+}
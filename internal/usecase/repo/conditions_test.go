@@ -0,0 +1,120 @@
+// Copyright 2024-2025 NetCracker Technology Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package repo
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Netcracker/qubership-disaster-recovery-daemon/api/entity"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+var conditionsPath = []string{"status", "conditions"}
+
+func TestSetConditions_switchoverTransitions(t *testing.T) {
+	cr := buildResource(1)
+
+	for _, status := range []string{entity.QUEUE, entity.RUNNING, entity.DONE} {
+		changed, err := setConditions(cr, conditionsPath, switchoverConditions(entity.SwitchoverState{Status: status}))
+		assert.NoErrorf(t, err, "Conditions should be set for '%s' status", status)
+		assert.Truef(t, changed, "Conditions should change for '%s' status", status)
+	}
+
+	conditions := getConditions(t, cr)
+	assert.Lenf(t, conditions, 2, "Conditions should be merged by type")
+	inProgress := meta.FindStatusCondition(conditions, entity.SwitchoverInProgressCondition)
+	assert.Equalf(t, metav1.ConditionFalse, inProgress.Status, "Switchover should not be in progress")
+	assert.Equalf(t, "SwitchoverFinished", inProgress.Reason, "Reason should be updated")
+	succeeded := meta.FindStatusCondition(conditions, entity.SwitchoverSucceededCondition)
+	assert.Equalf(t, metav1.ConditionTrue, succeeded.Status, "Switchover should succeed")
+
+	changed, err := setConditions(cr, conditionsPath, switchoverConditions(entity.SwitchoverState{Status: entity.FAILED}))
+	assert.NoErrorf(t, err, "Conditions should be set")
+	assert.Truef(t, changed, "Conditions should change")
+	succeeded = meta.FindStatusCondition(getConditions(t, cr), entity.SwitchoverSucceededCondition)
+	assert.Equalf(t, metav1.ConditionFalse, succeeded.Status, "Switchover should fail")
+	assert.Equalf(t, "SwitchoverFailed", succeeded.Reason, "Reason should be updated")
+}
+
+func TestSetConditions_keepTransitionTime(t *testing.T) {
+	cr := buildResource(1)
+	transitionTime := metav1.NewTime(time.Now().Add(-time.Hour).Truncate(time.Second))
+	condition := metav1.Condition{Type: entity.HealthyCondition, Status: metav1.ConditionTrue, Reason: "ServiceUp",
+		LastTransitionTime: transitionTime}
+	_, _ = setConditions(cr, conditionsPath, []metav1.Condition{condition})
+
+	changed, err := setConditions(cr, conditionsPath, []metav1.Condition{
+		{Type: entity.HealthyCondition, Status: metav1.ConditionTrue, Reason: "ServiceUp"},
+	})
+	assert.NoErrorf(t, err, "Conditions should be set")
+	assert.Falsef(t, changed, "The same condition should not change the resource")
+	healthy := meta.FindStatusCondition(getConditions(t, cr), entity.HealthyCondition)
+	assert.Truef(t, transitionTime.Equal(&healthy.LastTransitionTime), "Transition time should be kept")
+
+	changed, _ = setConditions(cr, conditionsPath, []metav1.Condition{
+		{Type: entity.HealthyCondition, Status: metav1.ConditionFalse, Reason: "ServiceDown"},
+	})
+	assert.Truef(t, changed, "Status change should change the resource")
+	healthy = meta.FindStatusCondition(getConditions(t, cr), entity.HealthyCondition)
+	assert.Truef(t, healthy.LastTransitionTime.After(transitionTime.Time), "Transition time should be updated")
+}
+
+func TestSetConditions_observedGeneration(t *testing.T) {
+	cr := buildResource(3)
+	_, _ = setConditions(cr, conditionsPath, switchoverConditions(entity.SwitchoverState{Status: entity.RUNNING}))
+	inProgress := meta.FindStatusCondition(getConditions(t, cr), entity.SwitchoverInProgressCondition)
+	assert.Equalf(t, int64(3), inProgress.ObservedGeneration, "Observed generation should be taken from resource")
+
+	cr.SetGeneration(4)
+	changed, _ := setConditions(cr, conditionsPath, switchoverConditions(entity.SwitchoverState{Status: entity.RUNNING}))
+	inProgress = meta.FindStatusCondition(getConditions(t, cr), entity.SwitchoverInProgressCondition)
+	assert.Truef(t, changed, "Generation change should change the resource")
+	assert.Equalf(t, int64(4), inProgress.ObservedGeneration, "Observed generation should be updated")
+}
+
+func buildResource(generation int64) *unstructured.Unstructured {
+	cr := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "ConfigMap",
+		"metadata": map[string]interface{}{
+			"name":      "example-service-dr-config",
+			"namespace": "my-namespace",
+		},
+	}}
+	cr.SetGeneration(generation)
+	return cr
+}
+
+func getConditions(t *testing.T, cr *unstructured.Unstructured) []metav1.Condition {
+	t.Helper()
+	items, _, err := unstructured.NestedSlice(cr.Object, conditionsPath...)
+	if err != nil {
+		t.Fatalf("Cannot read conditions: %v", err)
+	}
+	var conditions []metav1.Condition
+	for _, item := range items {
+		var condition metav1.Condition
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(item.(map[string]interface{}), &condition); err != nil {
+			t.Fatalf("Cannot decode condition: %v", err)
+		}
+		conditions = append(conditions, condition)
+	}
+	return conditions
+}
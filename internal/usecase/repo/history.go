@@ -0,0 +1,80 @@
+// Copyright 2024-2025 NetCracker Technology Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package repo
+
+import (
+	"time"
+
+	"github.com/Netcracker/qubership-disaster-recovery-daemon/api/entity"
+	"github.com/Netcracker/qubership-disaster-recovery-daemon/config"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// setHistory reflects the status update in the switchover history. The first record of the history is the latest
+// switchover, it is updated until switchover is finished, so server and controller share the same record.
+// It must be called before the new status is written to the resource, because previous mode is taken from it.
+func setHistory(cr *unstructured.Unstructured, drStatusPath config.DisasterRecoveryStatusPath,
+	update entity.SwitchoverState, now time.Time) error {
+	items, _, err := unstructured.NestedSlice(cr.Object, drStatusPath.HistoryPath...)
+	if err != nil {
+		return err
+	}
+	history := make([]entity.SwitchoverRecord, 0, len(items)+1)
+	for _, item := range items {
+		itemMap, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		var record entity.SwitchoverRecord
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(itemMap, &record); err != nil {
+			return err
+		}
+		history = append(history, record)
+	}
+
+	timestamp := now.UTC().Format(time.RFC3339)
+	if len(history) == 0 || history[0].EndTime != "" {
+		previousMode, _, _ := unstructured.NestedString(cr.Object, drStatusPath.ModePath...)
+		record := entity.SwitchoverRecord{PreviousMode: previousMode, StartTime: timestamp}
+		history = append([]entity.SwitchoverRecord{record}, history...)
+	}
+	latest := &history[0]
+	// server writes queue status with the current mode, requested mode is known only when controller starts
+	if update.Status != entity.QUEUE {
+		latest.Mode = update.Mode
+	}
+	latest.Status = update.Status
+	latest.Comment = update.Comment
+	if update.Status == entity.DONE || update.Status == entity.FAILED {
+		latest.EndTime = timestamp
+		if startTime, err := time.Parse(time.RFC3339, latest.StartTime); err == nil {
+			latest.Duration = now.UTC().Truncate(time.Second).Sub(startTime).String()
+		}
+	}
+
+	if drStatusPath.HistoryLength > 0 && len(history) > drStatusPath.HistoryLength {
+		history = history[:drStatusPath.HistoryLength]
+	}
+	result := make([]interface{}, 0, len(history))
+	for i := range history {
+		item, err := runtime.DefaultUnstructuredConverter.ToUnstructured(&history[i])
+		if err != nil {
+			return err
+		}
+		result = append(result, item)
+	}
+	return unstructured.SetNestedSlice(cr.Object, result, drStatusPath.HistoryPath...)
+}
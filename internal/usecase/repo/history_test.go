@@ -0,0 +1,108 @@
+// Copyright 2024-2025 NetCracker Technology Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package repo
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Netcracker/qubership-disaster-recovery-daemon/api/entity"
+	"github.com/Netcracker/qubership-disaster-recovery-daemon/config"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+var historyStatusPath = config.DisasterRecoveryStatusPath{
+	ModePath:    []string{"status", "mode"},
+	StatusPath:  []string{"status", "status"},
+	HistoryPath: []string{"status", "history"},
+}
+
+func TestSetHistory_createRecord(t *testing.T) {
+	cr := buildResource(1)
+	_ = unstructured.SetNestedField(cr.Object, entity.STANDBY, historyStatusPath.ModePath...)
+	now := time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)
+
+	err := setHistory(cr, historyStatusPath, entity.SwitchoverState{Mode: entity.STANDBY, Status: entity.QUEUE}, now)
+
+	assert.NoErrorf(t, err, "History should be set")
+	history := getHistory(t, cr)
+	assert.Lenf(t, history, 1, "Record should be created")
+	assert.Equalf(t, entity.SwitchoverRecord{PreviousMode: entity.STANDBY, Status: entity.QUEUE, StartTime: "2025-01-01T10:00:00Z"},
+		history[0], "Queue status should not set the requested mode")
+}
+
+func TestSetHistory_shareRecordAndDuration(t *testing.T) {
+	cr := buildResource(1)
+	_ = unstructured.SetNestedField(cr.Object, entity.STANDBY, historyStatusPath.ModePath...)
+	started := time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)
+
+	// queue status is written by server, running and done statuses are written by controller
+	_ = setHistory(cr, historyStatusPath, entity.SwitchoverState{Mode: entity.STANDBY, Status: entity.QUEUE}, started)
+	_ = setHistory(cr, historyStatusPath, entity.SwitchoverState{Mode: entity.ACTIVE, Status: entity.RUNNING},
+		started.Add(time.Second))
+	err := setHistory(cr, historyStatusPath, entity.SwitchoverState{Mode: entity.ACTIVE, Status: entity.DONE, Comment: "ok"},
+		started.Add(90*time.Second+500*time.Millisecond))
+
+	assert.NoErrorf(t, err, "History should be set")
+	history := getHistory(t, cr)
+	assert.Lenf(t, history, 1, "Server and controller should share the record")
+	assert.Equalf(t, entity.SwitchoverRecord{
+		Mode:         entity.ACTIVE,
+		PreviousMode: entity.STANDBY,
+		Status:       entity.DONE,
+		Comment:      "ok",
+		StartTime:    "2025-01-01T10:00:00Z",
+		EndTime:      "2025-01-01T10:01:30Z",
+		Duration:     "1m30s",
+	}, history[0], "Record should contain the result and duration")
+}
+
+func TestSetHistory_trimToLength(t *testing.T) {
+	cr := buildResource(1)
+	path := historyStatusPath
+	path.HistoryLength = 2
+	now := time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)
+
+	for i, mode := range []string{entity.ACTIVE, entity.STANDBY, entity.ACTIVE} {
+		_ = setHistory(cr, path, entity.SwitchoverState{Mode: mode, Status: entity.RUNNING}, now.Add(time.Duration(i)*time.Minute))
+		_ = setHistory(cr, path, entity.SwitchoverState{Mode: mode, Status: entity.FAILED}, now.Add(time.Duration(i)*time.Minute))
+		_ = unstructured.SetNestedField(cr.Object, mode, path.ModePath...)
+	}
+
+	history := getHistory(t, cr)
+	assert.Lenf(t, history, 2, "History should be trimmed to its length")
+	assert.Equalf(t, "2025-01-01T10:02:00Z", history[0].StartTime, "The latest record should be the first")
+	assert.Equalf(t, entity.STANDBY, history[0].PreviousMode, "Previous mode should be taken from status")
+	assert.Equalf(t, "2025-01-01T10:01:00Z", history[1].StartTime, "The oldest record should be removed")
+}
+
+func getHistory(t *testing.T, cr *unstructured.Unstructured) []entity.SwitchoverRecord {
+	t.Helper()
+	items, _, err := unstructured.NestedSlice(cr.Object, historyStatusPath.HistoryPath...)
+	if err != nil {
+		t.Fatalf("Cannot read history: %v", err)
+	}
+	var history []entity.SwitchoverRecord
+	for _, item := range items {
+		var record entity.SwitchoverRecord
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(item.(map[string]interface{}), &record); err != nil {
+			t.Fatalf("Cannot decode history record: %v", err)
+		}
+		history = append(history, record)
+	}
+	return history
+}
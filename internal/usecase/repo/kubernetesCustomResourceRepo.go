@@ -16,14 +16,20 @@ package repo
 
 import (
 	"context"
+	"fmt"
 	"github.com/Netcracker/qubership-disaster-recovery-daemon/api/entity"
 	"github.com/Netcracker/qubership-disaster-recovery-daemon/config"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/watch"
 	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/util/retry"
 	"log"
 	"strconv"
+	"time"
 )
 
 func NewKubernetesCustomResourceRepo(client dynamic.Interface,
@@ -46,10 +52,7 @@ type KubernetesCustomResourceRepo struct {
 }
 
 func (kcrr KubernetesCustomResourceRepo) GetDrMode(path ...string) (string, error) {
-	cr, err := kcrr.client.
-		Resource(kcrr.crGVR).
-		Namespace(kcrr.namespace).
-		Get(context.TODO(), kcrr.name, metav1.GetOptions{})
+	cr, err := kcrr.getResource()
 	if err != nil {
 		return "", err
 	}
@@ -61,14 +64,14 @@ func (kcrr KubernetesCustomResourceRepo) GetDrMode(path ...string) (string, erro
 }
 
 func (kcrr KubernetesCustomResourceRepo) GetDrStatus(path config.DisasterRecoveryStatusPath) (entity.SwitchoverState, error) {
-	cr, err := kcrr.client.
-		Resource(kcrr.crGVR).
-		Namespace(kcrr.namespace).
-		Get(context.TODO(), kcrr.name, metav1.GetOptions{})
+	cr, err := kcrr.getResource()
 	if err != nil {
 		return entity.SwitchoverState{}, err
 	}
+	return getDrStatus(cr, path)
+}
 
+func getDrStatus(cr *unstructured.Unstructured, path config.DisasterRecoveryStatusPath) (entity.SwitchoverState, error) {
 	drMode, _, err := unstructured.NestedString(cr.Object, path.ModePath...)
 	if err != nil {
 		return entity.SwitchoverState{}, err
@@ -95,11 +98,107 @@ func (kcrr KubernetesCustomResourceRepo) GetDrStatus(path config.DisasterRecover
 	return state, err
 }
 
+// WaitForStatus watches the resource until its status satisfies the condition or the context is done. The last known
+// status is returned in both cases.
+func (kcrr KubernetesCustomResourceRepo) WaitForStatus(ctx context.Context, path config.DisasterRecoveryStatusPath,
+	condition func(entity.SwitchoverState) bool) (entity.SwitchoverState, error) {
+	var state entity.SwitchoverState
+	err := kcrr.waitForResource(ctx, func(cr *unstructured.Unstructured) (bool, error) {
+		var err error
+		state, err = getDrStatus(cr, path)
+		return err == nil && condition(state), err
+	})
+	return state, err
+}
+
+// WaitForObservedGeneration watches the resource until the generation in the observed generation path of its status
+// is not less than the resource generation, i.e. the operator has picked up the latest spec.
+func (kcrr KubernetesCustomResourceRepo) WaitForObservedGeneration(ctx context.Context,
+	path config.DisasterRecoveryStatusPath) error {
+	return kcrr.waitForResource(ctx, func(cr *unstructured.Unstructured) (bool, error) {
+		observedGeneration, found, err := unstructured.NestedInt64(cr.Object, path.ObservedGenerationPath...)
+		return found && observedGeneration >= cr.GetGeneration(), err
+	})
+}
+
+// WaitForField watches the resource until the field by the path satisfies the condition or the context is done.
+// The condition receives a copy of the field, nil if the field is not found.
+func (kcrr KubernetesCustomResourceRepo) WaitForField(ctx context.Context, path []string,
+	condition func(interface{}) (bool, error)) error {
+	return kcrr.waitForResource(ctx, func(cr *unstructured.Unstructured) (bool, error) {
+		field, _, err := unstructured.NestedFieldCopy(cr.Object, path...)
+		if err != nil {
+			return false, err
+		}
+		return condition(field)
+	})
+}
+
+// waitForResource checks the resource and its changes until the condition is satisfied or the context is done.
+// The watch is restarted if it is closed by API server.
+func (kcrr KubernetesCustomResourceRepo) waitForResource(ctx context.Context,
+	condition func(*unstructured.Unstructured) (bool, error)) error {
+	for {
+		cr, err := kcrr.getResource()
+		if err != nil {
+			return err
+		}
+		if done, err := condition(cr); done || err != nil {
+			return err
+		}
+		watcher, err := kcrr.client.
+			Resource(kcrr.crGVR).
+			Namespace(kcrr.namespace).
+			Watch(ctx, metav1.ListOptions{
+				FieldSelector:   fields.OneTermEqualSelector("metadata.name", kcrr.name).String(),
+				ResourceVersion: cr.GetResourceVersion(),
+			})
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return err
+		}
+		done, err := watchResource(ctx, watcher, kcrr.name, condition)
+		watcher.Stop()
+		if done || err != nil {
+			return err
+		}
+	}
+}
+
+// watchResource reads resource events until the condition is satisfied, the context is done or the watch is closed.
+func watchResource(ctx context.Context, watcher watch.Interface, name string,
+	condition func(*unstructured.Unstructured) (bool, error)) (bool, error) {
+	for {
+		select {
+		case <-ctx.Done():
+			return true, ctx.Err()
+		case event, ok := <-watcher.ResultChan():
+			if !ok {
+				return false, nil
+			}
+			cr, isResource := event.Object.(*unstructured.Unstructured)
+			if !isResource || cr.GetName() != name {
+				continue
+			}
+			if event.Type == watch.Deleted {
+				return true, entity.ErrResourceNotFound
+			}
+			if done, err := condition(cr); done || err != nil {
+				return true, err
+			}
+		}
+	}
+}
+
+// GetResource returns the current state of DR resource.
+func (kcrr KubernetesCustomResourceRepo) GetResource() (*unstructured.Unstructured, error) {
+	return kcrr.getResource()
+}
+
 func (kcrr KubernetesCustomResourceRepo) GetResourceVersion() (string, error) {
-	cr, err := kcrr.client.
-		Resource(kcrr.crGVR).
-		Namespace(kcrr.namespace).
-		Get(context.TODO(), kcrr.name, metav1.GetOptions{})
+	cr, err := kcrr.getResource()
 	if err != nil {
 		return "", err
 	}
@@ -107,70 +206,192 @@ func (kcrr KubernetesCustomResourceRepo) GetResourceVersion() (string, error) {
 	return resourceVersion, err
 }
 
+// GetField returns a copy of the resource field by the given path and whether the field is found.
+func (kcrr KubernetesCustomResourceRepo) GetField(path ...string) (interface{}, bool, error) {
+	cr, err := kcrr.getResource()
+	if err != nil {
+		return nil, false, err
+	}
+	return unstructured.NestedFieldCopy(cr.Object, path...)
+}
+
+// UpdateAnnotations adds the given annotations to the resource, other annotations are kept.
+func (kcrr KubernetesCustomResourceRepo) UpdateAnnotations(annotations map[string]string) error {
+	log.Printf("Update annotations '%v' for resource '%v %s'", annotations, kcrr.crGVR, kcrr.name)
+	return kcrr.updateResource(func(cr *unstructured.Unstructured) (bool, error) {
+		setAnnotations(cr, annotations)
+		return true, nil
+	}, kcrr.update)
+}
+
+func setAnnotations(cr *unstructured.Unstructured, update map[string]string) {
+	annotations := cr.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	for key, value := range update {
+		annotations[key] = value
+	}
+	cr.SetAnnotations(annotations)
+}
+
 func (kcrr KubernetesCustomResourceRepo) UpdateDrMode(drPathConfig config.DisasterRecoveryPath,
 	update entity.ModeDataUpdate) error {
 	log.Printf("Update mode '%+v' for resource '%v %s'", update, kcrr.crGVR, kcrr.name)
-	cr, err := kcrr.client.
-		Resource(kcrr.crGVR).
-		Namespace(kcrr.namespace).
-		Get(context.TODO(), kcrr.name, metav1.GetOptions{})
+	return kcrr.updateResource(func(cr *unstructured.Unstructured) (bool, error) {
+		err := unstructured.SetNestedField(cr.Object, update.Mode, drPathConfig.ModePath...)
+		if err != nil {
+			return false, err
+		}
+		var noWait interface{}
+		if drPathConfig.NoWaitAsString {
+			noWait = strconv.FormatBool(update.NoWait)
+		} else {
+			noWait = update.NoWait
+		}
+		err = unstructured.SetNestedField(cr.Object, noWait, drPathConfig.NoWaitPath...)
+		if err != nil {
+			return false, err
+		}
+		if update.Annotation != nil {
+			setAnnotations(cr, update.Annotation)
+		}
+		return true, nil
+	}, kcrr.update)
+}
+
+func (kcrr KubernetesCustomResourceRepo) UpdateStatus(drStatusPath config.DisasterRecoveryStatusPath,
+	update entity.SwitchoverState, fields ...entity.StatusField) error {
+	log.Printf("Update status '%+v' for resource '%v %s'", update, kcrr.crGVR, kcrr.name)
+	return kcrr.updateResource(func(cr *unstructured.Unstructured) (bool, error) {
+		return true, setStatus(cr, drStatusPath, update, fields, time.Now())
+	}, func(cr *unstructured.Unstructured) error {
+		return kcrr.updateStatus(cr, drStatusPath)
+	})
+}
+
+// setStatus writes the switchover state with additional fields, history, operations and conditions to the resource.
+func setStatus(cr *unstructured.Unstructured, drStatusPath config.DisasterRecoveryStatusPath,
+	update entity.SwitchoverState, fields []entity.StatusField, now time.Time) error {
+	if len(drStatusPath.HistoryPath) > 0 {
+		if err := setHistory(cr, drStatusPath, update, now); err != nil {
+			return err
+		}
+	}
+	if len(drStatusPath.OperationsPath) > 0 {
+		if err := updateOperation(cr, drStatusPath, update, now); err != nil {
+			return err
+		}
+	}
+	err := unstructured.SetNestedField(cr.Object, update.Mode, drStatusPath.ModePath...)
 	if err != nil {
 		return err
 	}
-	err = unstructured.SetNestedField(cr.Object, update.Mode, drPathConfig.ModePath...)
+	err = unstructured.SetNestedField(cr.Object, update.Status, drStatusPath.StatusPath...)
 	if err != nil {
 		return err
 	}
-	var noWait interface{}
-	if drPathConfig.NoWaitAsString {
-		noWait = strconv.FormatBool(update.NoWait)
-	} else {
-		noWait = update.NoWait
-	}
-	err = unstructured.SetNestedField(cr.Object, noWait, drPathConfig.NoWaitPath...)
+	err = unstructured.SetNestedField(cr.Object, update.Comment, drStatusPath.CommentPath...)
 	if err != nil {
 		return err
 	}
-	if update.Annotation != nil {
-		annotations := cr.GetAnnotations()
-		if annotations == nil {
-			annotations = map[string]string{}
+	if err = setStatusFields(cr, fields); err != nil {
+		return err
+	}
+	if len(drStatusPath.ConditionsPath) > 0 {
+		if _, err = setConditions(cr, drStatusPath.ConditionsPath, switchoverConditions(update)); err != nil {
+			return err
 		}
-		for key, value := range update.Annotation {
-			annotations[key] = value
+	}
+	return nil
+}
+
+// UpdateConditions sets conditions in the resource status, the resource is updated only if conditions are changed.
+func (kcrr KubernetesCustomResourceRepo) UpdateConditions(drStatusPath config.DisasterRecoveryStatusPath,
+	conditions ...metav1.Condition) error {
+	if len(drStatusPath.ConditionsPath) == 0 || len(conditions) == 0 {
+		return nil
+	}
+	return kcrr.updateResource(func(cr *unstructured.Unstructured) (bool, error) {
+		return setConditions(cr, drStatusPath.ConditionsPath, conditions)
+	}, func(cr *unstructured.Unstructured) error {
+		return kcrr.updateStatus(cr, drStatusPath)
+	})
+}
+
+// UpdateStatusFields writes additional fields to the resource status, fields with empty path are skipped.
+func (kcrr KubernetesCustomResourceRepo) UpdateStatusFields(drStatusPath config.DisasterRecoveryStatusPath,
+	fields ...entity.StatusField) error {
+	var configuredFields []entity.StatusField
+	for _, field := range fields {
+		if len(field.Path) > 0 {
+			configuredFields = append(configuredFields, field)
 		}
-		cr.SetAnnotations(annotations)
 	}
+	if len(configuredFields) == 0 {
+		return nil
+	}
+	return kcrr.updateResource(func(cr *unstructured.Unstructured) (bool, error) {
+		return true, setStatusFields(cr, configuredFields)
+	}, func(cr *unstructured.Unstructured) error {
+		return kcrr.updateStatus(cr, drStatusPath)
+	})
+}
 
-	_, err = kcrr.client.
-		Resource(kcrr.crGVR).
-		Namespace(kcrr.namespace).
-		Update(context.TODO(), cr, metav1.UpdateOptions{})
-	return err
+func setStatusFields(cr *unstructured.Unstructured, fields []entity.StatusField) error {
+	for _, field := range fields {
+		if len(field.Path) == 0 {
+			continue
+		}
+		log.Printf("Update status field '%v' with value '%v' for resource '%s'", field.Path, field.Value, cr.GetName())
+		if err := unstructured.SetNestedField(cr.Object, field.Value, field.Path...); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
-func (kcrr KubernetesCustomResourceRepo) UpdateStatus(drStatusPath config.DisasterRecoveryStatusPath,
-	update entity.SwitchoverState) error {
-	log.Printf("Update status '%+v' for resource '%v %s'", update, kcrr.crGVR, kcrr.name)
+// getResource returns DR resource, missing resource is reported as entity.ErrResourceNotFound.
+func (kcrr KubernetesCustomResourceRepo) getResource() (*unstructured.Unstructured, error) {
 	cr, err := kcrr.client.
 		Resource(kcrr.crGVR).
 		Namespace(kcrr.namespace).
 		Get(context.TODO(), kcrr.name, metav1.GetOptions{})
-	if err != nil {
-		return err
-	}
-	err = unstructured.SetNestedField(cr.Object, update.Mode, drStatusPath.ModePath...)
-	if err != nil {
-		return err
-	}
-	err = unstructured.SetNestedField(cr.Object, update.Status, drStatusPath.StatusPath...)
-	if err != nil {
-		return err
-	}
-	err = unstructured.SetNestedField(cr.Object, update.Comment, drStatusPath.CommentPath...)
-	if err != nil {
-		return err
+	if apierrors.IsNotFound(err) {
+		return nil, fmt.Errorf("%w: %v", entity.ErrResourceNotFound, err)
 	}
+	return cr, err
+}
+
+// updateResource applies the change to the latest version of the resource and writes it with the given function.
+// The resource is changed by DRD server, controller and service operator, so the whole cycle is repeated on conflict.
+// The resource is not written if the change reports that nothing is changed.
+func (kcrr KubernetesCustomResourceRepo) updateResource(change func(*unstructured.Unstructured) (bool, error),
+	write func(*unstructured.Unstructured) error) error {
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		cr, err := kcrr.getResource()
+		if err != nil {
+			return err
+		}
+		changed, err := change(cr)
+		if err != nil || !changed {
+			return err
+		}
+		return write(cr)
+	})
+}
+
+func (kcrr KubernetesCustomResourceRepo) update(cr *unstructured.Unstructured) error {
+	_, err := kcrr.client.
+		Resource(kcrr.crGVR).
+		Namespace(kcrr.namespace).
+		Update(context.TODO(), cr, metav1.UpdateOptions{})
+	return err
+}
+
+func (kcrr KubernetesCustomResourceRepo) updateStatus(cr *unstructured.Unstructured,
+	drStatusPath config.DisasterRecoveryStatusPath) error {
+	var err error
 	if drStatusPath.TreatStatusAsField {
 		_, err = kcrr.client.
 			Resource(kcrr.crGVR).
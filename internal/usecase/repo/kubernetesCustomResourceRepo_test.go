@@ -0,0 +1,77 @@
+// Copyright 2024-2025 NetCracker Technology Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package repo
+
+import (
+	"testing"
+
+	"github.com/Netcracker/qubership-disaster-recovery-daemon/api/entity"
+	"github.com/Netcracker/qubership-disaster-recovery-daemon/config"
+	"github.com/stretchr/testify/assert"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+var configMapGVR = schema.GroupVersionResource{Version: "v1", Resource: "configmaps"}
+
+var statusPath = config.DisasterRecoveryStatusPath{
+	ModePath:           []string{"data", "status_mode"},
+	StatusPath:         []string{"data", "status_status"},
+	CommentPath:        []string{"data", "status_comment"},
+	TreatStatusAsField: true,
+}
+
+func TestKubernetesCustomResourceRepo_retryUpdateOnConflict(t *testing.T) {
+	client := dynamicfake.NewSimpleDynamicClient(runtime.NewScheme(), buildResource(1))
+	conflicts := 0
+	client.PrependReactor("update", "configmaps", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		if conflicts > 0 {
+			return false, nil, nil
+		}
+		conflicts++
+		return true, nil, apierrors.NewConflict(configMapGVR.GroupResource(), "example-service-dr-config", nil)
+	})
+	crRepo := NewKubernetesCustomResourceRepo(client, configMapGVR, "example-service-dr-config", "my-namespace")
+
+	err := crRepo.UpdateStatus(statusPath, entity.SwitchoverState{Mode: entity.ACTIVE, Status: entity.DONE})
+
+	assert.NoErrorf(t, err, "Conflict should be retried")
+	assert.Equalf(t, 1, conflicts, "Conflict should be returned once")
+	state, _ := crRepo.GetDrStatus(statusPath)
+	assert.Equalf(t, entity.DONE, state.Status, "Status should be written after conflict")
+}
+
+func TestKubernetesCustomResourceRepo_skipUnchangedConditions(t *testing.T) {
+	client := dynamicfake.NewSimpleDynamicClient(runtime.NewScheme(), buildResource(1))
+	crRepo := NewKubernetesCustomResourceRepo(client, configMapGVR, "example-service-dr-config", "my-namespace")
+	path := statusPath
+	path.ConditionsPath = conditionsPath
+	healthy := metav1.Condition{Type: entity.HealthyCondition, Status: metav1.ConditionTrue, Reason: "ServiceUp"}
+
+	_ = crRepo.UpdateConditions(path, healthy)
+	_ = crRepo.UpdateConditions(path, healthy)
+
+	updates := 0
+	for _, action := range client.Actions() {
+		if action.GetVerb() == "update" {
+			updates++
+		}
+	}
+	assert.Equalf(t, 1, updates, "Unchanged conditions should not be written")
+}
@@ -0,0 +1,47 @@
+// Copyright 2024-2025 NetCracker Technology Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package repo
+
+import (
+	"context"
+	"os"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/scheme"
+	typedcorev1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/tools/record"
+)
+
+// NewKubernetesEventRecorder creates recorder which sends events in the background until the context is done.
+func NewKubernetesEventRecorder(ctx context.Context, clientSet kubernetes.Interface, component string) *KubernetesEventRecorder {
+	host, _ := os.Hostname()
+	broadcaster := record.NewBroadcaster(record.WithContext(ctx))
+	broadcaster.StartRecordingToSink(&typedcorev1.EventSinkImpl{Interface: clientSet.CoreV1().Events("")})
+	return &KubernetesEventRecorder{
+		recorder: broadcaster.NewRecorder(scheme.Scheme, corev1.EventSource{Component: component, Host: host}),
+	}
+}
+
+// KubernetesEventRecorder creates Kubernetes Events for DR resource. Events are informational, so they are
+// aggregated and sent asynchronously by client-go broadcaster and never interrupt the switchover.
+type KubernetesEventRecorder struct {
+	recorder record.EventRecorder
+}
+
+func (ker KubernetesEventRecorder) Event(object runtime.Object, eventType string, reason string, message string) {
+	ker.recorder.Event(object, eventType, reason, message)
+}
@@ -23,7 +23,7 @@ import (
 	appsv1clients "k8s.io/client-go/kubernetes/typed/apps/v1"
 )
 
-func NewKubernetesRepo(clientSet *kubernetes.Clientset, namespace string) *KubernetesRepo {
+func NewKubernetesRepo(clientSet kubernetes.Interface, namespace string) *KubernetesRepo {
 	return &KubernetesRepo{
 		deploymentsClient:  clientSet.AppsV1().Deployments(namespace),
 		statefulSetsClient: clientSet.AppsV1().StatefulSets(namespace),
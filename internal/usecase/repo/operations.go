@@ -0,0 +1,163 @@
+// Copyright 2024-2025 NetCracker Technology Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package repo
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/Netcracker/qubership-disaster-recovery-daemon/api/entity"
+	"github.com/Netcracker/qubership-disaster-recovery-daemon/config"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// AddOperation adds the pending operation to the resource status. Operations which are not finished yet are marked
+// as failed, because the new operation supersedes them.
+func (kcrr KubernetesCustomResourceRepo) AddOperation(drStatusPath config.DisasterRecoveryStatusPath,
+	operation entity.Operation) error {
+	if len(drStatusPath.OperationsPath) == 0 {
+		return nil
+	}
+	log.Printf("Add operation '%+v' for resource '%v %s'", operation, kcrr.crGVR, kcrr.name)
+	return kcrr.updateResource(func(cr *unstructured.Unstructured) (bool, error) {
+		return true, addOperation(cr, drStatusPath, operation, time.Now())
+	}, func(cr *unstructured.Unstructured) error {
+		return kcrr.updateStatus(cr, drStatusPath)
+	})
+}
+
+func addOperation(cr *unstructured.Unstructured, drStatusPath config.DisasterRecoveryStatusPath,
+	operation entity.Operation, now time.Time) error {
+	operations, err := getOperations(cr, drStatusPath)
+	if err != nil {
+		return err
+	}
+	for i := range operations {
+		if !operations[i].IsFinished() {
+			operations[i].Phase = entity.OperationFailed
+			operations[i].Comment = fmt.Sprintf("Superseded by operation '%s'", operation.ID)
+			operations[i].CompletionTime = now.UTC().Format(time.RFC3339)
+		}
+	}
+	operation.Phase = entity.OperationPending
+	operation.CreationTime = now.UTC().Format(time.RFC3339)
+	operations = append([]entity.Operation{operation}, operations...)
+	return setOperations(cr, drStatusPath, operations, now)
+}
+
+// FailOperation marks the operation with the given ID as failed if it is not finished yet. It is used when
+// the switchover cannot be requested after the operation was added, so the operation does not stay pending.
+func (kcrr KubernetesCustomResourceRepo) FailOperation(drStatusPath config.DisasterRecoveryStatusPath,
+	id string, comment string) error {
+	if len(drStatusPath.OperationsPath) == 0 {
+		return nil
+	}
+	log.Printf("Fail operation '%s' for resource '%v %s': %s", id, kcrr.crGVR, kcrr.name, comment)
+	return kcrr.updateResource(func(cr *unstructured.Unstructured) (bool, error) {
+		return failOperation(cr, drStatusPath, id, comment, time.Now())
+	}, func(cr *unstructured.Unstructured) error {
+		return kcrr.updateStatus(cr, drStatusPath)
+	})
+}
+
+func failOperation(cr *unstructured.Unstructured, drStatusPath config.DisasterRecoveryStatusPath,
+	id string, comment string, now time.Time) (bool, error) {
+	operations, err := getOperations(cr, drStatusPath)
+	if err != nil {
+		return false, err
+	}
+	for i := range operations {
+		if operations[i].ID != id || operations[i].IsFinished() {
+			continue
+		}
+		operations[i].Phase = entity.OperationFailed
+		operations[i].Comment = comment
+		operations[i].CompletionTime = now.UTC().Format(time.RFC3339)
+		return true, setOperations(cr, drStatusPath, operations, now)
+	}
+	return false, nil
+}
+
+// updateOperation reflects the status update in the latest operation if it is not finished yet. Queue status is
+// written by server before the operation is picked up by controller, so it does not change the operation.
+func updateOperation(cr *unstructured.Unstructured, drStatusPath config.DisasterRecoveryStatusPath,
+	update entity.SwitchoverState, now time.Time) error {
+	operations, err := getOperations(cr, drStatusPath)
+	if err != nil {
+		return err
+	}
+	if len(operations) > 0 && !operations[0].IsFinished() && update.Status != entity.QUEUE {
+		latest := &operations[0]
+		timestamp := now.UTC().Format(time.RFC3339)
+		if latest.StartTime == "" {
+			latest.StartTime = timestamp
+		}
+		latest.Status = update.Status
+		latest.Comment = update.Comment
+		switch update.Status {
+		case entity.RUNNING:
+			latest.Phase = entity.OperationRunning
+		case entity.DONE:
+			latest.Phase = entity.OperationSucceeded
+			latest.CompletionTime = timestamp
+		case entity.FAILED:
+			latest.Phase = entity.OperationFailed
+			latest.CompletionTime = timestamp
+		}
+	}
+	return setOperations(cr, drStatusPath, operations, now)
+}
+
+func getOperations(cr *unstructured.Unstructured, drStatusPath config.DisasterRecoveryStatusPath) ([]entity.Operation, error) {
+	items, _, err := unstructured.NestedSlice(cr.Object, drStatusPath.OperationsPath...)
+	if err != nil {
+		return nil, err
+	}
+	operations := make([]entity.Operation, 0, len(items))
+	for _, item := range items {
+		itemMap, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		var operation entity.Operation
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(itemMap, &operation); err != nil {
+			return nil, err
+		}
+		operations = append(operations, operation)
+	}
+	return operations, nil
+}
+
+// setOperations writes operations to the resource, finished operations are removed when retention period expires.
+func setOperations(cr *unstructured.Unstructured, drStatusPath config.DisasterRecoveryStatusPath,
+	operations []entity.Operation, now time.Time) error {
+	result := make([]interface{}, 0, len(operations))
+	for i := range operations {
+		if operations[i].IsFinished() && drStatusPath.OperationsRetention > 0 {
+			completionTime, err := time.Parse(time.RFC3339, operations[i].CompletionTime)
+			if err == nil && now.Sub(completionTime) > drStatusPath.OperationsRetention {
+				continue
+			}
+		}
+		item, err := runtime.DefaultUnstructuredConverter.ToUnstructured(&operations[i])
+		if err != nil {
+			return err
+		}
+		result = append(result, item)
+	}
+	return unstructured.SetNestedSlice(cr.Object, result, drStatusPath.OperationsPath...)
+}
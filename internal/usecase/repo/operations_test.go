@@ -0,0 +1,61 @@
+// Copyright 2024-2025 NetCracker Technology Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package repo
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Netcracker/qubership-disaster-recovery-daemon/api/entity"
+	"github.com/Netcracker/qubership-disaster-recovery-daemon/config"
+	"github.com/stretchr/testify/assert"
+)
+
+var operationsStatusPath = config.DisasterRecoveryStatusPath{
+	ModePath:       []string{"status", "mode"},
+	StatusPath:     []string{"status", "status"},
+	OperationsPath: []string{"status", "operations"},
+}
+
+func TestFailOperation_failPendingOperation(t *testing.T) {
+	cr := buildResource(1)
+	now := time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)
+	_ = addOperation(cr, operationsStatusPath, entity.Operation{ID: "first", Mode: entity.STANDBY}, now)
+	_ = addOperation(cr, operationsStatusPath, entity.Operation{ID: "second", Mode: entity.ACTIVE}, now)
+
+	changed, err := failOperation(cr, operationsStatusPath, "second", "resource is not updated", now.Add(time.Second))
+
+	assert.NoErrorf(t, err, "Operation should be failed")
+	assert.Truef(t, changed, "Resource should be changed")
+	operations, _ := getOperations(cr, operationsStatusPath)
+	assert.Equalf(t, entity.OperationFailed, operations[0].Phase, "Pending operation should be failed")
+	assert.Equalf(t, "resource is not updated", operations[0].Comment, "Comment should contain the error")
+	assert.Equalf(t, "2025-01-01T10:00:01Z", operations[0].CompletionTime, "Completion time should be set")
+	assert.Equalf(t, "Superseded by operation 'second'", operations[1].Comment, "Other operations should not be changed")
+}
+
+func TestFailOperation_skipFinishedOperation(t *testing.T) {
+	cr := buildResource(1)
+	now := time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)
+	_ = addOperation(cr, operationsStatusPath, entity.Operation{ID: "first", Mode: entity.STANDBY}, now)
+	_ = updateOperation(cr, operationsStatusPath, entity.SwitchoverState{Mode: entity.STANDBY, Status: entity.DONE}, now)
+
+	changed, err := failOperation(cr, operationsStatusPath, "first", "resource is not updated", now)
+
+	assert.NoErrorf(t, err, "Finished operation should be skipped")
+	assert.Falsef(t, changed, "Resource should not be changed")
+	operations, _ := getOperations(cr, operationsStatusPath)
+	assert.Equalf(t, entity.OperationSucceeded, operations[0].Phase, "Finished operation should be kept")
+}
@@ -15,56 +15,81 @@
 package usecase
 
 import (
+	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"github.com/Netcracker/qubership-disaster-recovery-daemon/api/entity"
 	"github.com/Netcracker/qubership-disaster-recovery-daemon/config"
+	"github.com/Netcracker/qubership-disaster-recovery-daemon/metrics"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/uuid"
+	"log"
 	"strconv"
 	"time"
 )
 
+// DryRunRequest is kept in DR resource annotation until DR controller writes the verdict with the same ID.
+type DryRunRequest struct {
+	ID     string `json:"id"`
+	Mode   string `json:"mode"`
+	NoWait bool   `json:"noWait"`
+}
+
+// CancelRequest is kept in DR resource annotation, DR controller cancels the switchover once for every ID.
+type CancelRequest struct {
+	ID     string `json:"id"`
+	Caller string `json:"caller"`
+}
+
 const (
 	SwitchoverAnnotationKey     = "switchoverRetry"
+	DryRunAnnotationKey         = "dryRunRequest"
+	CancelAnnotationKey         = "switchoverCancel"
 	CustomResourceNotFoundError = "The custom resource does not contain information about disaster recovery. Error is [%v]"
-	updateStatusSleepDuration   = 2 * time.Second
+	statusConfirmTimeout        = 10 * time.Second
+	modePickupTimeout           = 30 * time.Second
 )
 
-func NewSetModeUseCase(crr KubernetesCustomResourceRepo, config config.DisasterRecoveryPath) *SetModeUseCase {
+// NewSetModeUseCase creates use case for switchover requests. If no-wait is false in the request,
+// the response is sent when switchover is finished, but not later than waitTimeout. Dry-run verdict
+// is waited not longer than dryRunTimeout. Background work which outlives the request, such as recording
+// of mode pickup latency, is stopped when ctx is done.
+func NewSetModeUseCase(ctx context.Context, crr KubernetesCustomResourceRepo, config config.DisasterRecoveryPath,
+	er EventRecorder, waitTimeout time.Duration, dryRunTimeout time.Duration) *SetModeUseCase {
 	return &SetModeUseCase{
-		crRepo: crr,
-		config: config,
+		ctx:           ctx,
+		crRepo:        crr,
+		config:        config,
+		eventRecorder: er,
+		waitTimeout:   waitTimeout,
+		dryRunTimeout: dryRunTimeout,
+		pickups:       make(chan struct{}, 1),
 	}
 }
 
 type SetModeUseCase struct {
-	crRepo KubernetesCustomResourceRepo
-	config config.DisasterRecoveryPath
+	ctx           context.Context
+	crRepo        KubernetesCustomResourceRepo
+	config        config.DisasterRecoveryPath
+	eventRecorder EventRecorder
+	waitTimeout   time.Duration
+	dryRunTimeout time.Duration
+	pickups       chan struct{}
 }
 
-func (smuc SetModeUseCase) SetDrMode(data entity.RequestData) (entity.SwitchoverState, error) {
-	mode := data.Mode
-	if mode != entity.ACTIVE && mode != entity.STANDBY && mode != entity.DISABLED {
-		return entity.SwitchoverState{Mode: mode,
-				Status: entity.FAILED,
-				Comment: fmt.Sprintf("'%s' mode is not in the allowed list. Please, use '%s', '%s' or '%s'",
-					mode, entity.ACTIVE, entity.STANDBY, entity.DISABLED)},
-			fmt.Errorf("illegal mode field value - [%s]", mode)
-	}
-	drStatus, err := smuc.crRepo.GetDrStatus(smuc.config.StatusPath)
-	if err != nil {
-		return entity.SwitchoverState{Comment: fmt.Sprintf(CustomResourceNotFoundError, err)}, err
+func (smuc SetModeUseCase) SetDrMode(ctx context.Context, data entity.RequestData) (entity.SwitchoverState, error) {
+	if data.DryRun {
+		return entity.SwitchoverState{Mode: data.Mode, Status: entity.FAILED,
+				Comment: "Dry-run request must be processed by DryRun method"},
+			errors.New("dry-run request is passed to SetDrMode")
 	}
-
-	drMode, err := smuc.crRepo.GetDrMode(smuc.config.ModePath...)
+	mode := data.Mode
+	resource, drStatus, drMode, failure, err := smuc.validate(mode)
 	if err != nil {
-		return entity.SwitchoverState{Comment: fmt.Sprintf(CustomResourceNotFoundError, err)}, err
-	}
-
-	if drStatus.Status == entity.RUNNING {
-		return entity.SwitchoverState{
-				Mode:    mode,
-				Comment: "The switchover process is in progress. Please, wait until it will be finished"},
-			errors.New("switchover process is already in progress")
+		return failure, err
 	}
 
 	if drStatus.Mode == drMode &&
@@ -82,12 +107,26 @@ func (smuc SetModeUseCase) SetDrMode(data entity.RequestData) (entity.Switchover
 		noWait = *data.NoWait
 	}
 
+	var operationID string
+	if len(smuc.config.StatusPath.OperationsPath) > 0 {
+		operationID = string(uuid.NewUUID())
+		err = smuc.crRepo.AddOperation(smuc.config.StatusPath, entity.Operation{ID: operationID, Mode: mode})
+		if err != nil {
+			return entity.SwitchoverState{Mode: mode, Comment: err.Error()}, err
+		}
+	}
+
 	err = smuc.crRepo.UpdateStatus(smuc.config.StatusPath,
-		entity.SwitchoverState{Mode: drStatus.Mode, Status: entity.QUEUE, Comment: "Switchover is in queue"})
+		entity.SwitchoverState{Mode: drStatus.Mode, Status: entity.QUEUE, Comment: "Switchover is in queue"},
+		entity.StatusField{Path: smuc.config.StatusPath.StartTimePath, Value: time.Now().UTC().Format(time.RFC3339)})
 	if err != nil {
-		return entity.SwitchoverState{Mode: mode, Comment: err.Error()}, err
+		return smuc.failOperation(mode, operationID, err)
+	}
+	smuc.eventRecorder.Event(resource, corev1.EventTypeNormal, entity.SwitchoverQueuedReason,
+		fmt.Sprintf("Switchover from '%s' to '%s' mode is in queue", drStatus.Mode, mode))
+	if err = smuc.confirmQueueStatus(ctx); err != nil {
+		return smuc.failOperation(mode, operationID, err)
 	}
-	time.Sleep(updateStatusSleepDuration)
 
 	update := entity.ModeDataUpdate{Mode: mode, NoWait: noWait}
 	// we should send CR to the reconcile loop if switchover from standby to active was failed
@@ -99,9 +138,210 @@ func (smuc SetModeUseCase) SetDrMode(data entity.RequestData) (entity.Switchover
 	}
 	err = smuc.crRepo.UpdateDrMode(smuc.config, update)
 	if err != nil {
-		return entity.SwitchoverState{Mode: mode, Comment: err.Error()}, err
+		return smuc.failOperation(mode, operationID, err)
+	}
+	smuc.recordModePickup(mode)
+	if noWait {
+		return entity.SwitchoverState{Mode: mode, OperationID: operationID}, nil
+	}
+	return smuc.waitForSwitchover(ctx, mode, operationID)
+}
+
+// confirmQueueStatus waits until queue status is observed in DR resource, so the mode is not changed
+// before the status update is applied.
+func (smuc SetModeUseCase) confirmQueueStatus(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, statusConfirmTimeout)
+	defer cancel()
+	_, err := smuc.crRepo.WaitForStatus(ctx, smuc.config.StatusPath, func(state entity.SwitchoverState) bool {
+		return state.Status == entity.QUEUE
+	})
+	if errors.Is(err, context.DeadlineExceeded) {
+		return fmt.Errorf("queue status is not observed in DR resource within %v", statusConfirmTimeout)
+	}
+	return err
+}
+
+// recordModePickup records the pickup latency in the background, since the caller does not wait for the operator.
+// It does nothing if the observed generation path is not specified, or if status is treated as a field, since then
+// every status write increases the generation itself. Only one pickup is watched at a time, so latency of
+// the switchover which is requested meanwhile is not recorded.
+func (smuc SetModeUseCase) recordModePickup(mode string) {
+	if len(smuc.config.StatusPath.ObservedGenerationPath) == 0 || smuc.config.StatusPath.TreatStatusAsField {
+		return
+	}
+	select {
+	case smuc.pickups <- struct{}{}:
+	default:
+		log.Printf("Pickup of '%s' mode is not recorded, pickup of the previous mode is still watched", mode)
+		return
+	}
+	go func() {
+		defer func() { <-smuc.pickups }()
+		smuc.waitForModePickup(mode)
+	}()
+}
+
+// waitForModePickup waits until the operator reports the new generation of DR resource as observed and records
+// the pickup latency. The switchover is not failed if the operator is slow, it is only logged.
+func (smuc SetModeUseCase) waitForModePickup(mode string) {
+	started := time.Now()
+	ctx, cancel := context.WithTimeout(smuc.ctx, modePickupTimeout)
+	defer cancel()
+	if err := smuc.crRepo.WaitForObservedGeneration(ctx, smuc.config.StatusPath); err != nil {
+		log.Printf("Operator has not picked up '%s' mode: %v", mode, err)
+		return
+	}
+	pickupDuration := time.Since(started)
+	log.Printf("Operator has picked up '%s' mode in %v", mode, pickupDuration)
+	metrics.ObserveModePickup(mode, pickupDuration)
+}
+
+// waitForSwitchover watches DR resource until switchover is done or failed, or the request context is done.
+// If the wait timeout is exceeded, the current status is returned, so the caller can continue to poll it.
+func (smuc SetModeUseCase) waitForSwitchover(ctx context.Context, mode string, operationID string) (entity.SwitchoverState, error) {
+	ctx, cancel := context.WithTimeout(ctx, smuc.waitTimeout)
+	defer cancel()
+	state, err := smuc.crRepo.WaitForStatus(ctx, smuc.config.StatusPath, func(state entity.SwitchoverState) bool {
+		return state.Status == entity.DONE || state.Status == entity.FAILED
+	})
+	state.OperationID = operationID
+	if errors.Is(err, context.DeadlineExceeded) {
+		log.Printf("Switchover to '%s' mode is not finished within %v, current status is '%s'", mode, smuc.waitTimeout, state.Status)
+		state.Comment = fmt.Sprintf("The switchover is not finished within %v, please, check the status later", smuc.waitTimeout)
+		return state, nil
+	}
+	if err != nil {
+		return entity.SwitchoverState{Mode: mode, Comment: err.Error(), OperationID: operationID}, err
+	}
+	return state, nil
+}
+
+// DryRun performs the same validations as SetDrMode and asks DR controller to check whether the switchover
+// would succeed. DR resource mode is not changed, the verdict of DR controller is returned.
+func (smuc SetModeUseCase) DryRun(ctx context.Context, data entity.RequestData) (entity.DryRunResult, error) {
+	mode := data.Mode
+	if _, _, _, failure, err := smuc.validate(mode); err != nil {
+		return entity.DryRunResult{Mode: mode, Verdict: entity.DryRunFailed, Comment: failure.Comment}, err
+	}
+	dryRunPath := smuc.config.StatusPath.DryRunPath
+	if len(dryRunPath) == 0 {
+		return entity.DryRunResult{Mode: mode, Verdict: entity.DryRunFailed,
+				Comment: "Dry-run is not configured, DISASTER_RECOVERY_STATUS_DRY_RUN_PATH must be specified"},
+			errors.New("dry-run status path is not specified")
+	}
+	noWait := data.NoWait == nil || *data.NoWait
+	request := DryRunRequest{ID: strconv.FormatInt(time.Now().UnixNano(), 10), Mode: mode, NoWait: noWait}
+	annotation, err := json.Marshal(request)
+	if err != nil {
+		return entity.DryRunResult{Mode: mode, Verdict: entity.DryRunFailed, Comment: err.Error()}, err
+	}
+	if err = smuc.crRepo.UpdateAnnotations(map[string]string{DryRunAnnotationKey: string(annotation)}); err != nil {
+		return entity.DryRunResult{Mode: mode, Verdict: entity.DryRunFailed, Comment: err.Error()}, err
+	}
+	ctx, cancel := context.WithTimeout(ctx, smuc.dryRunTimeout)
+	defer cancel()
+	var result entity.DryRunResult
+	err = smuc.crRepo.WaitForField(ctx, dryRunPath, func(field interface{}) (bool, error) {
+		var err error
+		result, err = decodeDryRunResult(field)
+		return err == nil && result.ID == request.ID, err
+	})
+	if errors.Is(err, context.DeadlineExceeded) {
+		return entity.DryRunResult{ID: request.ID, Mode: mode, Verdict: entity.DryRunFailed,
+				Comment: fmt.Sprintf("DR controller has not reported dry-run verdict within %v", smuc.dryRunTimeout)},
+			errors.New("dry-run timeout is exceeded")
+	}
+	if err != nil {
+		return entity.DryRunResult{Mode: mode, Verdict: entity.DryRunFailed, Comment: err.Error()}, err
+	}
+	return result, nil
+}
+
+// Cancel asks DR controller to cancel queued or running switchover. The switchover is marked as failed
+// by DR controller, so the method returns as soon as the request is written to DR resource.
+func (smuc SetModeUseCase) Cancel(caller string) (entity.SwitchoverState, error) {
+	resource, err := smuc.crRepo.GetResource()
+	if err != nil {
+		return entity.SwitchoverState{Comment: fmt.Sprintf(CustomResourceNotFoundError, err)}, err
+	}
+	drStatus, err := smuc.crRepo.GetDrStatus(smuc.config.StatusPath)
+	if err != nil {
+		return entity.SwitchoverState{Comment: fmt.Sprintf(CustomResourceNotFoundError, err)}, err
+	}
+	if drStatus.Status != entity.QUEUE && drStatus.Status != entity.RUNNING {
+		return entity.SwitchoverState{Mode: drStatus.Mode, Status: drStatus.Status,
+				Comment: fmt.Sprintf("The switchover cannot be cancelled in '%s' status", drStatus.Status)},
+			entity.ErrNoSwitchoverInProgress
+	}
+	request := CancelRequest{ID: strconv.FormatInt(time.Now().UnixNano(), 10), Caller: caller}
+	annotation, err := json.Marshal(request)
+	if err != nil {
+		return entity.SwitchoverState{Mode: drStatus.Mode, Comment: err.Error()}, err
+	}
+	if err = smuc.crRepo.UpdateAnnotations(map[string]string{CancelAnnotationKey: string(annotation)}); err != nil {
+		return entity.SwitchoverState{Mode: drStatus.Mode, Comment: err.Error()}, err
+	}
+	smuc.eventRecorder.Event(resource, corev1.EventTypeNormal, entity.SwitchoverCancelRequestedReason,
+		fmt.Sprintf("Cancellation of switchover to '%s' mode is requested by %s", drStatus.Mode, caller))
+	return entity.SwitchoverState{Mode: drStatus.Mode, Status: drStatus.Status,
+		Comment: "The switchover cancellation is requested"}, nil
+}
+
+// failOperation marks the operation as failed when the switchover cannot be requested, so the caller
+// does not wait for the operation which is never picked up by DR controller.
+func (smuc SetModeUseCase) failOperation(mode string, operationID string, err error) (entity.SwitchoverState, error) {
+	if operationID != "" {
+		if failErr := smuc.crRepo.FailOperation(smuc.config.StatusPath, operationID, err.Error()); failErr != nil {
+			log.Printf("Cannot mark operation '%s' as failed: %v", operationID, failErr)
+		}
+	}
+	return entity.SwitchoverState{Mode: mode, Comment: err.Error()}, err
+}
+
+// validate checks that switchover to the mode can be requested. It returns DR resource with its current status and mode,
+// or the response for Site Manager and an error if the request must be rejected.
+func (smuc SetModeUseCase) validate(mode string) (*unstructured.Unstructured, entity.SwitchoverState, string, entity.SwitchoverState, error) {
+	if mode != entity.ACTIVE && mode != entity.STANDBY && mode != entity.DISABLED {
+		return nil, entity.SwitchoverState{}, "", entity.SwitchoverState{Mode: mode,
+				Status: entity.FAILED,
+				Comment: fmt.Sprintf("'%s' mode is not in the allowed list. Please, use '%s', '%s' or '%s'",
+					mode, entity.ACTIVE, entity.STANDBY, entity.DISABLED)},
+			fmt.Errorf("illegal mode field value - [%s]", mode)
+	}
+	drStatus, err := smuc.crRepo.GetDrStatus(smuc.config.StatusPath)
+	if err != nil {
+		return nil, entity.SwitchoverState{}, "", entity.SwitchoverState{Comment: fmt.Sprintf(CustomResourceNotFoundError, err)}, err
+	}
+
+	resource, err := smuc.crRepo.GetResource()
+	if err != nil {
+		return nil, entity.SwitchoverState{}, "", entity.SwitchoverState{Comment: fmt.Sprintf(CustomResourceNotFoundError, err)}, err
+	}
+	drMode, _, err := unstructured.NestedString(resource.Object, smuc.config.ModePath...)
+	if err != nil {
+		return nil, entity.SwitchoverState{}, "", entity.SwitchoverState{Comment: fmt.Sprintf(CustomResourceNotFoundError, err)}, err
+	}
+
+	if drStatus.Status == entity.RUNNING {
+		return nil, entity.SwitchoverState{}, "", entity.SwitchoverState{
+				Mode:    mode,
+				Comment: "The switchover process is in progress. Please, wait until it will be finished"},
+			errors.New("switchover process is already in progress")
+	}
+	return resource, drStatus, drMode, entity.SwitchoverState{}, nil
+}
+
+func decodeDryRunResult(field interface{}) (entity.DryRunResult, error) {
+	var result entity.DryRunResult
+	if field == nil {
+		return result, nil
+	}
+	fieldMap, ok := field.(map[string]interface{})
+	if !ok {
+		return result, fmt.Errorf("dry-run status field has unexpected type %T", field)
 	}
-	return entity.SwitchoverState{Mode: mode}, nil
+	err := runtime.DefaultUnstructuredConverter.FromUnstructured(fieldMap, &result)
+	return result, err
 }
 
 func isRetryAction(drMode string, drStatus entity.SwitchoverState, newMode string) bool {
@@ -0,0 +1,55 @@
+// Copyright 2024-2025 NetCracker Technology Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package usecase
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Netcracker/qubership-disaster-recovery-daemon/config"
+	"github.com/stretchr/testify/assert"
+)
+
+// pickupCustomResourceRepo blocks waiting for observed generation until the context is done
+type pickupCustomResourceRepo struct {
+	KubernetesCustomResourceRepo
+	waits atomic.Int32
+}
+
+func (t *pickupCustomResourceRepo) WaitForObservedGeneration(ctx context.Context, _ config.DisasterRecoveryStatusPath) error {
+	t.waits.Add(1)
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func TestSetModeUseCase_stopModePickupWithContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	crRepo := &pickupCustomResourceRepo{}
+	drConfig := config.DisasterRecoveryPath{}
+	drConfig.StatusPath.ObservedGenerationPath = []string{"status", "observedGeneration"}
+	smuc := NewSetModeUseCase(ctx, crRepo, drConfig, nil, time.Minute, time.Minute)
+
+	smuc.recordModePickup("active")
+	smuc.recordModePickup("standby")
+	assert.Eventuallyf(t, func() bool { return crRepo.waits.Load() == 1 }, time.Second, 10*time.Millisecond,
+		"Pickup should be watched")
+	cancel()
+
+	assert.Eventuallyf(t, func() bool { return len(smuc.pickups) == 0 }, time.Second, 10*time.Millisecond,
+		"Pickup should stop when context is done")
+	assert.Equalf(t, int32(1), crRepo.waits.Load(), "Only one pickup should be watched at a time")
+}
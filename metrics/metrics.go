@@ -0,0 +1,170 @@
+// Copyright 2024-2025 NetCracker Technology Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package metrics keeps Prometheus metrics of DRD server and controller. Metrics are registered in the default
+// Prometheus registry, so they are exposed together with metrics of the service which embeds DRD.
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Netcracker/qubership-disaster-recovery-daemon/api/entity"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const (
+	namespace         = "drd"
+	unknownLabelValue = "unknown"
+)
+
+// Reasons of authentication failures
+const (
+	MissingTokenReason     = "missing_token"
+	TokenReviewErrorReason = "token_review_error"
+	UnauthenticatedReason  = "unauthenticated"
+	ForbiddenAccountReason = "forbidden_service_account"
+)
+
+var (
+	httpRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "Number of HTTP requests to DRD server by route, method and response code.",
+	}, []string{"route", "method", "code"})
+	httpRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "Duration of HTTP requests to DRD server by route and method.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"route", "method"})
+	authenticationFailures = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "authentication_failures_total",
+		Help:      "Number of rejected requests to DRD server by reason.",
+	}, []string{"reason"})
+	healthChecks = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "health_checks_total",
+		Help:      "Number of service health checks by DR mode and health status.",
+	}, []string{"mode", "status"})
+	health = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "health_status",
+		Help:      "Result of the last service health check by DR mode and health status, the value is always 1.",
+	}, []string{"mode", "status"})
+	mode = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "mode",
+		Help:      "Current DR mode from DR resource status, 1 for the current mode and 0 for others.",
+	}, []string{"mode"})
+	switchoverStatus = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "switchover_status",
+		Help:      "Current switchover status from DR resource status, 1 for the current status and 0 for others.",
+	}, []string{"status"})
+	switchovers = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "switchovers_total",
+		Help:      "Number of finished switchovers by DR mode and result status.",
+	}, []string{"mode", "status"})
+	switchoverDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "switchover_duration_seconds",
+		Help:      "Duration of finished switchovers by DR mode and result status.",
+		Buckets:   prometheus.ExponentialBuckets(1, 2, 14),
+	}, []string{"mode", "status"})
+	modePickupDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "mode_pickup_duration_seconds",
+		Help:      "Time from the mode update in DR resource until the operator reports the new generation as observed.",
+		Buckets:   prometheus.ExponentialBuckets(0.05, 2, 12),
+	}, []string{"mode"})
+	switchoverRetries = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "switchover_retries_total",
+		Help:      "Number of DR function attempts which failed and were retried by DR mode.",
+	}, []string{"mode"})
+)
+
+var (
+	modes    = []string{entity.ACTIVE, entity.STANDBY, entity.DISABLED}
+	statuses = []string{entity.QUEUE, entity.RUNNING, entity.DONE, entity.FAILED}
+)
+
+// Handler returns HTTP handler which exposes metrics from the default Prometheus registry.
+func Handler() http.Handler {
+	return promhttp.Handler()
+}
+
+// ObserveHTTPRequest records HTTP request to DRD server. Route is a path template, so the number of series is limited.
+func ObserveHTTPRequest(route string, method string, code int, duration time.Duration) {
+	httpRequests.WithLabelValues(route, method, strconv.Itoa(code)).Inc()
+	httpRequestDuration.WithLabelValues(route, method).Observe(duration.Seconds())
+}
+
+// ObserveAuthenticationFailure records request which is rejected by authenticator.
+func ObserveAuthenticationFailure(reason string) {
+	authenticationFailures.WithLabelValues(reason).Inc()
+}
+
+// ObserveHealth records result of service health check in the given DR mode.
+func ObserveHealth(drMode string, status string) {
+	healthChecks.WithLabelValues(labelValue(drMode), labelValue(status)).Inc()
+	health.Reset()
+	health.WithLabelValues(labelValue(drMode), labelValue(status)).Set(1)
+}
+
+// SetState sets gauges of the current DR mode and switchover status. Known modes and statuses are always present,
+// so the gauges can be used in alerts without absent() checks.
+func SetState(state entity.SwitchoverState) {
+	setOneOf(mode, modes, state.Mode)
+	setOneOf(switchoverStatus, statuses, state.Status)
+}
+
+// ObserveSwitchover records finished switchover.
+func ObserveSwitchover(drMode string, status string, duration time.Duration) {
+	switchovers.WithLabelValues(labelValue(drMode), labelValue(status)).Inc()
+	switchoverDuration.WithLabelValues(labelValue(drMode), labelValue(status)).Observe(duration.Seconds())
+}
+
+// ObserveModePickup records how long the operator took to pick up the requested mode.
+func ObserveModePickup(drMode string, duration time.Duration) {
+	modePickupDuration.WithLabelValues(labelValue(drMode)).Observe(duration.Seconds())
+}
+
+// ObserveRetry records failed attempt of DR function which is retried.
+func ObserveRetry(drMode string) {
+	switchoverRetries.WithLabelValues(labelValue(drMode)).Inc()
+}
+
+func setOneOf(gauge *prometheus.GaugeVec, known []string, current string) {
+	gauge.Reset()
+	for _, value := range known {
+		gauge.WithLabelValues(value).Set(0)
+	}
+	if current != "" {
+		gauge.WithLabelValues(current).Set(1)
+	}
+}
+
+func labelValue(value string) string {
+	if value == "" {
+		return unknownLabelValue
+	}
+	return value
+}
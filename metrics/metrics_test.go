@@ -0,0 +1,66 @@
+// Copyright 2024-2025 NetCracker Technology Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Netcracker/qubership-disaster-recovery-daemon/api/entity"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSetState(t *testing.T) {
+	SetState(entity.SwitchoverState{Mode: entity.STANDBY, Status: entity.RUNNING})
+	SetState(entity.SwitchoverState{Mode: entity.ACTIVE, Status: entity.DONE})
+
+	assert.Equalf(t, 1.0, testutil.ToFloat64(mode.WithLabelValues(entity.ACTIVE)), "Current mode should be set")
+	assert.Equalf(t, 0.0, testutil.ToFloat64(mode.WithLabelValues(entity.STANDBY)), "Previous mode should be reset")
+	assert.Equalf(t, 0.0, testutil.ToFloat64(mode.WithLabelValues(entity.DISABLED)), "Known mode should be present")
+	assert.Equalf(t, 1.0, testutil.ToFloat64(switchoverStatus.WithLabelValues(entity.DONE)), "Current status should be set")
+	assert.Equalf(t, 0.0, testutil.ToFloat64(switchoverStatus.WithLabelValues(entity.RUNNING)), "Previous status should be reset")
+}
+
+func TestObserveSwitchover(t *testing.T) {
+	before := testutil.ToFloat64(switchovers.WithLabelValues(entity.ACTIVE, entity.FAILED))
+	ObserveSwitchover(entity.ACTIVE, entity.FAILED, time.Minute)
+	ObserveRetry("")
+
+	assert.Equalf(t, before+1, testutil.ToFloat64(switchovers.WithLabelValues(entity.ACTIVE, entity.FAILED)),
+		"Switchover should be counted")
+	assert.Equalf(t, 1.0, testutil.ToFloat64(switchoverRetries.WithLabelValues(unknownLabelValue)),
+		"Retry without mode should be counted as unknown")
+
+	ObserveModePickup(entity.ACTIVE, time.Second)
+	assert.Equalf(t, 1, testutil.CollectAndCount(modePickupDuration), "Mode pickup should be observed")
+}
+
+func TestHandler(t *testing.T) {
+	ObserveHTTPRequest("/sitemanager", http.MethodPost, http.StatusOK, time.Millisecond)
+	ObserveAuthenticationFailure(MissingTokenReason)
+	recorder := httptest.NewRecorder()
+
+	Handler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	body := recorder.Body.String()
+	assert.Truef(t, strings.Contains(body, `drd_http_requests_total{code="200",method="POST",route="/sitemanager"} 1`),
+		"HTTP requests should be exposed")
+	assert.Truef(t, strings.Contains(body, `drd_authentication_failures_total{reason="missing_token"} 1`),
+		"Authentication failures should be exposed")
+}
@@ -15,20 +15,45 @@
 package httpserver
 
 import (
+	"context"
 	"crypto/tls"
+	"errors"
 	"fmt"
 	"github.com/Netcracker/qubership-disaster-recovery-daemon/config"
+	"net"
 	"net/http"
+	"time"
 )
 
+const shutdownTimeout = 10 * time.Second
+
 func StartServer(handler http.Handler, config config.ServerConfig) error {
+	return StartServerWithContext(context.Background(), handler, config)
+}
+
+// StartServerWithContext serves requests until the context is done, then the server is shut down gracefully.
+// Request contexts are derived from the given context, so they are cancelled on shutdown.
+func StartServerWithContext(ctx context.Context, handler http.Handler, config config.ServerConfig) error {
 	server := &http.Server{
-		Addr:    fmt.Sprintf(":%d", config.Port),
-		Handler: handler,
+		Addr:        fmt.Sprintf(":%d", config.Port),
+		Handler:     handler,
+		BaseContext: func(net.Listener) context.Context { return ctx },
 	}
+	stopped := context.AfterFunc(ctx, func() {
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		_ = server.Shutdown(shutdownCtx)
+	})
+	defer stopped()
+	var err error
 	if config.TLSEnabled {
 		server.TLSConfig = &tls.Config{CipherSuites: config.Suites}
-		return server.ListenAndServeTLS(fmt.Sprintf("%s/tls.crt", config.CertsPath), fmt.Sprintf("%s/tls.key", config.CertsPath))
+		err = server.ListenAndServeTLS(fmt.Sprintf("%s/tls.crt", config.CertsPath), fmt.Sprintf("%s/tls.key", config.CertsPath))
+	} else {
+		err = server.ListenAndServe()
+	}
+	if errors.Is(err, http.ErrServerClosed) {
+		return nil
 	}
-	return server.ListenAndServe()
+	return err
 }
@@ -15,14 +15,21 @@
 package server
 
 import (
+	"context"
 	"github.com/Netcracker/qubership-disaster-recovery-daemon/api/entity"
+	"github.com/Netcracker/qubership-disaster-recovery-daemon/client"
 	"github.com/Netcracker/qubership-disaster-recovery-daemon/config"
 	"github.com/Netcracker/qubership-disaster-recovery-daemon/internal/app"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
 	"log"
 )
 
 type Server struct {
-	config *config.Config
+	ctx       context.Context
+	config    *config.Config
+	clientSet kubernetes.Interface
+	dynClient dynamic.Interface
 }
 
 func NewServer(config *config.Config) *Server {
@@ -35,7 +42,31 @@ func (srv *Server) WithHealthFunc(healthFunc func(request entity.HealthRequest)
 	return srv
 }
 
+// WithClients sets Kubernetes clients which are used by the server instead of clients built from the environment,
+// e.g. to share one client set with DR controller or to run the server against fake clients.
+func (srv *Server) WithClients(clientSet kubernetes.Interface, dynClient dynamic.Interface) *Server {
+	srv.clientSet = clientSet
+	srv.dynClient = dynClient
+	return srv
+}
+
+// WithContext sets the context which stops the server and its background work when it is done.
+// By default, the server runs until the process exits.
+func (srv *Server) WithContext(ctx context.Context) *Server {
+	srv.ctx = ctx
+	return srv
+}
+
 func (srv *Server) Run() {
+	if srv.ctx == nil {
+		srv.ctx = context.Background()
+	}
+	if srv.clientSet == nil {
+		srv.clientSet = client.MakeKubeClientSet()
+	}
+	if srv.dynClient == nil {
+		srv.dynClient = client.MakeDynamicClient()
+	}
 	log.Println("DR server started")
-	app.Run(srv.config)
+	app.Run(srv.ctx, srv.config, srv.clientSet, srv.dynClient)
 }